@@ -0,0 +1,208 @@
+package rgo
+
+/*
+#define USE_RINTERNALS
+#include <Rinternals.h>
+*/
+import "C"
+import "fmt"
+
+// NewArray creates a new array given the size of each dimension and a slice of data, which should already be in R's
+// column-major order. NewArray makes a copy of the input slices, so that changing them later will not affect the array.
+// If any dimension is negative, an InvalidIndex error is returned. If the dimensions don't match the length of the
+// data, an ImpossibleMatrix error is returned.
+func NewArray(dim []int, data []float64) (*Array, error) {
+	for _, d := range dim {
+		if d < 0 {
+			return nil, InvalidIndex
+		}
+	}
+
+	out := &Array{Dim: make([]int, len(dim)), Data: make([]float64, len(data))}
+	copy(out.Dim, dim)
+	copy(out.Data, data)
+	if !out.isSizeValid() {
+		return nil, ImpossibleMatrix
+	}
+	return out, nil
+}
+
+// ArrayFromMatrix creates a two dimensional array with the same data and names as the input matrix. The array is a
+// copy, so it can be changed without changing the matrix.
+func ArrayFromMatrix(m Matrix) Array {
+	cm := CopyMatrix(m)
+	out := Array{Dim: []int{cm.Nrow, cm.Ncol}, Data: cm.Data}
+	if cm.RowNames != nil || cm.ColNames != nil {
+		out.Dimnames = [][]string{cm.RowNames, cm.ColNames}
+	}
+	return out
+}
+
+// ToMatrix creates a matrix with the same data and names as a two dimensional array. The matrix is a copy, so it can be
+// changed without changing the array. If the array doesn't have exactly two dimensions, a SizeMismatch error is
+// returned, and if its names don't match its dimensions, a LengthMismatch error is returned.
+func (a *Array) ToMatrix() (*Matrix, error) {
+	if !a.isSizeValid() {
+		return nil, ImpossibleMatrix
+	}
+	if len(a.Dim) != 2 {
+		return nil, SizeMismatch
+	}
+	if !a.areNamesValid() {
+		return nil, fmt.Errorf("%w: problem is number of dimnames vs. dimensions", LengthMismatch)
+	}
+
+	out := &Matrix{Nrow: a.Dim[0], Ncol: a.Dim[1], Data: make([]float64, len(a.Data))}
+	copy(out.Data, a.Data)
+	if a.Dimnames != nil {
+		out.RowNames = copyNames(a.Dimnames[0])
+		out.ColNames = copyNames(a.Dimnames[1])
+	}
+	return out, nil
+}
+
+// offset finds the position in the data slice of the element at the given index, using 0-based indexing. There must
+// be one index for each dimension, or it will return a SizeMismatch error.
+func (a *Array) offset(index []int) (int, error) {
+	if !a.isSizeValid() {
+		return 0, ImpossibleMatrix
+	}
+	if len(index) != len(a.Dim) {
+		return 0, SizeMismatch
+	}
+
+	offset, stride := 0, 1
+	for k, ind := range index {
+		if ind < 0 {
+			return 0, InvalidIndex
+		}
+		if ind >= a.Dim[k] {
+			return 0, IndexOutOfBounds
+		}
+		offset += ind * stride
+		stride *= a.Dim[k]
+	}
+	return offset, nil
+}
+
+// GetInd returns the value in the element of the array defined by the inputs, which are one 0-based index for each
+// dimension. If the number of indices doesn't match the number of dimensions, it will return a SizeMismatch error.
+func (a *Array) GetInd(index ...int) (float64, error) {
+	offset, err := a.offset(index)
+	if err != nil {
+		return 0, err
+	}
+	return a.Data[offset], nil
+}
+
+// SetInd sets the value in the element of the array defined by the index inputs, which are one 0-based index for each
+// dimension. If the number of indices doesn't match the number of dimensions, it will return a SizeMismatch error.
+func (a *Array) SetInd(data float64, index ...int) error {
+	offset, err := a.offset(index)
+	if err != nil {
+		return err
+	}
+	a.Data[offset] = data
+	return nil
+}
+
+// Slice returns the part of the array at the given index along one dimension, which is the same as a[, , index] in R
+// for the third dimension. The output has one less dimension than the input, since the sliced dimension is dropped.
+// For example, slicing a time x latitude x longitude array along the first axis returns the latitude x longitude grid
+// at one point in time.
+//
+// Both the axis and the index are 0-based. If either is negative, it will return an InvalidIndex error, and if either
+// is too big, it will return an IndexOutOfBounds error. If the array's names don't match its dimensions, a
+// LengthMismatch error is returned. The output is a copy, so it can be changed without changing the original array.
+func (a *Array) Slice(axis, index int) (*Array, error) {
+	if !a.isSizeValid() {
+		return nil, ImpossibleMatrix
+	}
+	if !a.areNamesValid() {
+		return nil, fmt.Errorf("%w: problem is number of dimnames vs. dimensions", LengthMismatch)
+	}
+	if axis < 0 || index < 0 {
+		return nil, InvalidIndex
+	}
+	if axis >= len(a.Dim) || index >= a.Dim[axis] {
+		return nil, IndexOutOfBounds
+	}
+
+	out := &Array{Dim: make([]int, 0, len(a.Dim)-1)}
+	out.Dim = append(out.Dim, a.Dim[:axis]...)
+	out.Dim = append(out.Dim, a.Dim[axis+1:]...)
+	if a.Dimnames != nil {
+		out.Dimnames = make([][]string, 0, len(a.Dim)-1)
+		for k, names := range a.Dimnames {
+			if k != axis {
+				out.Dimnames = append(out.Dimnames, copyNames(names))
+			}
+		}
+	}
+
+	// the elements to keep are in runs of the stride of the axis, so the order of the rest is already right
+	stride := 1
+	for _, d := range a.Dim[:axis] {
+		stride *= d
+	}
+	out.Data = make([]float64, 0, len(a.Data)/a.Dim[axis])
+	for start := index * stride; start < len(a.Data); start += stride * a.Dim[axis] {
+		out.Data = append(out.Data, a.Data[start:start+stride]...)
+	}
+
+	return out, nil
+}
+
+// AsArray returns an array based on the input RSEXP, which can be a double, integer, or logical vector with any number
+// of dimensions. Like AsMatrix, the data are always converted to float64s, and NA values become R's NA_real_. A
+// vector without a dimension attribute becomes a one dimensional array. The data returned is a copy of the data
+// contained in the RSEXP that can be modified independently. If the RSEXP is not one of the allowed types, the
+// TypeMismatch error is returned.
+func AsArray(r RSEXP) (out Array, err error) {
+	var na []bool
+	out.Data, na, err = asMatrixNumbers[float64](r, TYPEOF(r))
+	if err != nil {
+		return out, err
+	}
+	for i, isNA := range na {
+		if isNA {
+			out.Data[i] = float64(C.R_NaReal)
+		}
+	}
+
+	out.Dim, err = GetDim(r)
+	if err != nil {
+		return out, err
+	}
+	if out.Dim == nil {
+		out.Dim = []int{len(out.Data)}
+	}
+
+	out.Dimnames, err = GetDimnames(r)
+	return out, err
+}
+
+// ArrayToRSEXP converts an Array into a C.SEXP, represented by the returned RSEXP data. The R representation will have
+// the same data, dimensions, and names as the input Array and be of the REALSXP type (aka a double in R). If the
+// dimensions don't match the length of the data, an ImpossibleMatrix error is returned, and if the names don't match
+// the dimensions, a LengthMismatch error is returned.
+func ArrayToRSEXP(in Array) (*RSEXP, error) {
+	if !in.isSizeValid() {
+		return nil, ImpossibleMatrix
+	}
+
+	var ps ProtectStack
+	defer ps.Unprotect()
+	s := ps.Protect(NumericToRSEXP(in.Data))
+
+	if err := SetDim(*s, in.Dim); err != nil {
+		return nil, err
+	}
+	if in.Dimnames != nil {
+		if err := SetDimnames(*s, in.Dimnames); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
@@ -0,0 +1,143 @@
+package rgo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// a 2 x 3 x 2 array, where each element's value is its 1-based index in R's order
+var startingArray = Array{
+	Dim:      []int{2, 3, 2},
+	Data:     []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
+	Dimnames: [][]string{{"a", "b"}, nil, {"x", "y"}},
+}
+
+func TestNewArray(t *testing.T) {
+	if _, err := NewArray([]int{2, -3}, nil); err != InvalidIndex {
+		t.Errorf("expected an invalid index error but got %v", err)
+	}
+	if _, err := NewArray([]int{2, 3, 2}, []float64{1, 2, 3}); err != ImpossibleMatrix {
+		t.Errorf("expected an impossible matrix error but got %v", err)
+	}
+
+	data := []float64{1, 2, 3, 4, 5, 6}
+	arr, err := NewArray([]int{1, 2, 3}, data)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	data[0] = 3.14
+	if arr.Data[0] != 1 {
+		t.Error("changing the slice changed the array after creation")
+	}
+}
+
+func TestArray_GetInd(t *testing.T) {
+	// [2, 1, 2] in R
+	f, err := startingArray.GetInd(1, 0, 1)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	if f != 8 {
+		t.Errorf("expected 8 but got %v", f)
+	}
+
+	if _, err := startingArray.GetInd(1, 0); err != SizeMismatch {
+		t.Errorf("expected a size mismatch but got %v", err)
+	}
+	if _, err := startingArray.GetInd(1, -1, 0); err != InvalidIndex {
+		t.Errorf("expected an invalid index but got %v", err)
+	}
+	if _, err := startingArray.GetInd(1, 3, 0); err != IndexOutOfBounds {
+		t.Errorf("expected an index out of bounds but got %v", err)
+	}
+}
+
+func TestArray_Slice(t *testing.T) {
+	tests := []struct {
+		axis, index int
+		want        Array
+	}{
+		// a[2, , ] in R
+		{0, 1, Array{Dim: []int{3, 2}, Data: []float64{2, 4, 6, 8, 10, 12}, Dimnames: [][]string{nil, {"x", "y"}}}},
+		// a[, 3, ] in R
+		{1, 2, Array{Dim: []int{2, 2}, Data: []float64{5, 6, 11, 12}, Dimnames: [][]string{{"a", "b"}, {"x", "y"}}}},
+		// a[, , 1] in R
+		{2, 0, Array{Dim: []int{2, 3}, Data: []float64{1, 2, 3, 4, 5, 6}, Dimnames: [][]string{{"a", "b"}, nil}}},
+	}
+
+	for _, test := range tests {
+		got, err := startingArray.Slice(test.axis, test.index)
+		if err != nil {
+			t.Fatalf("got unexpected error slicing axis %d: %v", test.axis, err)
+		}
+		if !reflect.DeepEqual(*got, test.want) {
+			t.Errorf("slicing axis %d at %d: expected %v but got %v", test.axis, test.index, test.want, *got)
+		}
+	}
+
+	if _, err := startingArray.Slice(3, 0); err != IndexOutOfBounds {
+		t.Errorf("expected an index out of bounds but got %v", err)
+	}
+	if _, err := startingArray.Slice(0, -1); err != InvalidIndex {
+		t.Errorf("expected an invalid index but got %v", err)
+	}
+}
+
+func TestArrayFromMatrix(t *testing.T) {
+	arr := ArrayFromMatrix(startingMatrix)
+	if !reflect.DeepEqual(arr.Dim, []int{3, 2}) {
+		t.Errorf("expected dimensions [3 2] but got %v", arr.Dim)
+	}
+
+	mat, err := arr.ToMatrix()
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	if !AreMatricesEqual(*mat, startingMatrix) {
+		t.Errorf("expected %v but got %v", startingMatrix, *mat)
+	}
+
+	if _, err := startingArray.ToMatrix(); err != SizeMismatch {
+		t.Errorf("expected a size mismatch but got %v", err)
+	}
+
+	// names have to match the dimensions, rather than causing a panic
+	badNames := []struct {
+		name     string
+		dimnames [][]string
+	}{
+		{"too few dimensions", [][]string{{"a", "b", "c"}}},
+		{"too many dimensions", [][]string{nil, nil, nil}},
+		{"too few row names", [][]string{{"a", "b"}, nil}},
+		{"too many column names", [][]string{nil, {"x", "y", "z"}}},
+	}
+	for _, test := range badNames {
+		bad := ArrayFromMatrix(startingMatrix)
+		bad.Dimnames = test.dimnames
+		if _, err := bad.ToMatrix(); !errors.Is(err, LengthMismatch) {
+			t.Errorf("%s: expected a length mismatch but got %v", test.name, err)
+		}
+		if _, err := bad.Slice(0, 0); !errors.Is(err, LengthMismatch) {
+			t.Errorf("%s: expected a length mismatch from Slice but got %v", test.name, err)
+		}
+	}
+}
+
+func TestArrayToRSEXP(t *testing.T) {
+	withR(t, func(t testing.TB, ps *ProtectStack) {
+		r, err := ArrayToRSEXP(startingArray)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ps.Protect(r)
+
+		out, err := AsArray(*r)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(out, startingArray) {
+			t.Errorf("expected %v but got %v", startingArray, out)
+		}
+	})
+}
@@ -0,0 +1,240 @@
+package rgo
+
+/*
+#define USE_RINTERNALS
+#include <stdlib.h>
+#include <Rinternals.h>
+// setAttrib raises an R error when the attribute is invalid, like dimensions that don't match the length of the data.
+// An R error jumps straight back to R, skipping over any Go code in between, so we run it with R_ToplevelExec, which
+// catches the error and returns FALSE instead.
+typedef struct {
+	SEXP s;
+	SEXP name;
+	SEXP value;
+} attribArgs;
+void doSetAttrib(void *data) {
+	attribArgs *args = (attribArgs *) data;
+	setAttrib(args->s, args->name, args->value);
+}
+int safeSetAttrib(SEXP s, SEXP name, SEXP value) {
+	attribArgs args = {s, name, value};
+	return R_ToplevelExec(doSetAttrib, &args);
+}
+const char *tagName(SEXP node) {
+	return CHAR(PRINTNAME(TAG(node)));
+}
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// GetAttr returns the attribute of an RSEXP with the given name, such as "names" or "class". If the RSEXP doesn't have
+// the attribute, ok is false. The returned RSEXP is the attribute itself rather than a copy, so its data can be
+// extracted with the other AsX functions.
+func GetAttr(r RSEXP, name string) (attr RSEXP, ok bool) {
+	s := C.getAttrib(r, installSymbol(name))
+	if s == C.R_NilValue {
+		return nil, false
+	}
+	return RSEXP(s), true
+}
+
+// SetAttr sets the attribute of an RSEXP with the given name to the provided value, replacing it if it already exists.
+// A nil value removes the attribute. Any attribute can be set, so this can be used to add units, custom S3 classes,
+// or other metadata that R packages expect.
+//
+// R checks some attributes for validity, like making sure the dimensions of a matrix match the length of its data. If
+// R refuses to set the attribute, an RError is returned.
+func SetAttr(r RSEXP, name string, value *RSEXP) error {
+	var ps ProtectStack
+	defer ps.Unprotect()
+
+	valSEXP := C.R_NilValue
+	if value != nil {
+		valSEXP = ps.protect(C.SEXP(*value))
+	}
+	if C.safeSetAttrib(r, installSymbol(name), valSEXP) == 0 {
+		return fmt.Errorf("%w: could not set attribute %s", RError, name)
+	}
+	return nil
+}
+
+// Attributes returns every attribute of an RSEXP, in the order R stores them. Each attribute is the attribute itself
+// rather than a copy.
+//
+// R stores some attributes in a compact form which GetAttr expands. In particular, the automatic row names of a data
+// frame are returned here as c(NA, -nrow) rather than the row numbers.
+func Attributes(r RSEXP) []NamedElement {
+	var out []NamedElement
+	for node := C.ATTRIB(r); node != C.R_NilValue; node = C.CDR(node) {
+		out = append(out, NamedElement{
+			Name:  C.GoString(C.tagName(node)),
+			Value: RSEXP(C.CAR(node)),
+		})
+	}
+	return out
+}
+
+// GetNames returns the names attribute of an RSEXP as a slice of strings. If it doesn't have names, the output is nil.
+func GetNames(r RSEXP) ([]string, error) {
+	return getStringAttr(r, "names")
+}
+
+// SetNames sets the names attribute of an RSEXP. If the number of names doesn't match the length of the RSEXP, a
+// LengthMismatch error is returned. A nil slice removes the names.
+func SetNames(r RSEXP, names []string) error {
+	if names != nil && len(names) != LENGTH(r) {
+		return fmt.Errorf("%w: problem is number of names vs. length", LengthMismatch)
+	}
+	return setStringAttr(r, "names", names)
+}
+
+// GetClass returns the class attribute of an RSEXP as a slice of strings. Objects without a class attribute, like
+// plain vectors, have an implicit class in R, but the output here is nil.
+func GetClass(r RSEXP) ([]string, error) {
+	return getStringAttr(r, "class")
+}
+
+// SetClass sets the class attribute of an RSEXP, which is how S3 classes work in R. The most specific class should be
+// first. A nil slice removes the class.
+func SetClass(r RSEXP, class []string) error {
+	return setStringAttr(r, "class", class)
+}
+
+// GetComment returns the comment attribute of an RSEXP, which R uses for notes that aren't printed with the object.
+// If it doesn't have a comment, the output is nil.
+func GetComment(r RSEXP) ([]string, error) {
+	return getStringAttr(r, "comment")
+}
+
+// SetComment sets the comment attribute of an RSEXP. A nil slice removes the comment.
+func SetComment(r RSEXP, comment []string) error {
+	return setStringAttr(r, "comment", comment)
+}
+
+// GetDim returns the dim attribute of an RSEXP, which holds the size of each dimension of a matrix or array. If it
+// doesn't have dimensions, the output is nil.
+func GetDim(r RSEXP) ([]int, error) {
+	attr, ok := GetAttr(r, "dim")
+	if !ok {
+		return nil, nil
+	}
+	return AsNumeric[int](attr)
+}
+
+// SetDim sets the dim attribute of an RSEXP, which turns a vector into a matrix or array. If any dimension is negative,
+// an InvalidIndex error is returned. If the product of the dimensions doesn't match the length of the RSEXP, a
+// SizeMismatch error is returned. A nil slice removes the dimensions.
+func SetDim(r RSEXP, dim []int) error {
+	if dim == nil {
+		return SetAttr(r, "dim", nil)
+	}
+
+	size := 1
+	for _, d := range dim {
+		if d < 0 {
+			return InvalidIndex
+		}
+		size *= d
+	}
+	if size != LENGTH(r) {
+		return SizeMismatch
+	}
+
+	var ps ProtectStack
+	defer ps.Unprotect()
+	dimSEXP, err := IntegerToRSEXP(dim)
+	if err != nil {
+		return err
+	}
+	return SetAttr(r, "dim", ps.Protect(dimSEXP))
+}
+
+// GetDimnames returns the dimnames attribute of an RSEXP, which holds the names along each dimension of a matrix or
+// array, like its row and column names. There is one element of the output for each dimension, which is nil if that
+// dimension has no names. If the RSEXP has no dimnames at all, the output is nil.
+func GetDimnames(r RSEXP) ([][]string, error) {
+	attr, ok := GetAttr(r, "dimnames")
+	if !ok {
+		return nil, nil
+	}
+	elements, err := AsList(attr)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([][]string, len(elements))
+	for i, elem := range elements {
+		if elem == RSEXP(C.R_NilValue) {
+			continue
+		}
+		out[i], err = AsCharacter[string](elem)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// SetDimnames sets the dimnames attribute of an RSEXP. There must be one element of the input for each dimension, and
+// each element must either be nil (no names for that dimension) or have the same length as the dimension. If any of
+// these don't match, a LengthMismatch error is returned. A nil slice removes the dimnames.
+func SetDimnames(r RSEXP, dimnames [][]string) error {
+	if dimnames == nil {
+		return SetAttr(r, "dimnames", nil)
+	}
+
+	dim, err := GetDim(r)
+	if err != nil {
+		return err
+	}
+	if len(dim) != len(dimnames) {
+		return fmt.Errorf("%w: problem is number of dimnames vs. dimensions", LengthMismatch)
+	}
+
+	var ps ProtectStack
+	defer ps.Unprotect()
+
+	elements := make([]*RSEXP, len(dimnames))
+	for i, names := range dimnames {
+		if names == nil {
+			elements[i] = rNull()
+			continue
+		}
+		if len(names) != dim[i] {
+			return fmt.Errorf("%w: problem is number of names in dimension %d", LengthMismatch, i)
+		}
+		elements[i] = ps.Protect(CharacterToRSEXP(names))
+	}
+	return SetAttr(r, "dimnames", ps.Protect(MakeList(elements...)))
+}
+
+// getStringAttr returns an attribute that is a character vector, or nil if the attribute doesn't exist.
+func getStringAttr(r RSEXP, name string) ([]string, error) {
+	attr, ok := GetAttr(r, name)
+	if !ok {
+		return nil, nil
+	}
+	return AsCharacter[string](attr)
+}
+
+// setStringAttr sets an attribute to a character vector, or removes it if the input is nil.
+func setStringAttr(r RSEXP, name string, value []string) error {
+	if value == nil {
+		return SetAttr(r, name, nil)
+	}
+
+	var ps ProtectStack
+	defer ps.Unprotect()
+	return SetAttr(r, name, ps.Protect(CharacterToRSEXP(value)))
+}
+
+// installSymbol returns R's symbol with the given name, creating it if it doesn't exist yet. Symbols are never garbage
+// collected, so they don't need to be protected.
+func installSymbol(name string) C.SEXP {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	return C.Rf_install(cname)
+}
@@ -0,0 +1,84 @@
+package rgo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSetAttributes(t *testing.T) {
+	withR(t, func(t testing.TB, ps *ProtectStack) {
+		r := ps.Protect(NumericToRSEXP([]float64{1, 2, 3, 4, 5, 6}))
+
+		// names have to match the length of the vector
+		if err := SetNames(*r, []string{"a", "b"}); !errors.Is(err, LengthMismatch) {
+			t.Errorf("expected a length mismatch but got %v", err)
+		}
+		names := []string{"a", "b", "c", "d", "e", "f"}
+		if err := SetNames(*r, names); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if out, _ := GetNames(*r); !reflect.DeepEqual(out, names) {
+			t.Errorf("expected names %v but got %v", names, out)
+		}
+
+		// dimensions have to match the length too
+		if err := SetDim(*r, []int{4, 2}); err != SizeMismatch {
+			t.Errorf("expected a size mismatch but got %v", err)
+		}
+		if err := SetDim(*r, []int{-3, -2}); err != InvalidIndex {
+			t.Errorf("expected an invalid index but got %v", err)
+		}
+		if err := SetDim(*r, []int{3, 2}); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if out, _ := GetDim(*r); !reflect.DeepEqual(out, []int{3, 2}) {
+			t.Errorf("expected dimensions [3 2] but got %v", out)
+		}
+
+		// dimnames need one entry per dimension, and nil means that dimension has no names
+		if err := SetDimnames(*r, [][]string{{"x", "y"}, nil}); !errors.Is(err, LengthMismatch) {
+			t.Errorf("expected a length mismatch but got %v", err)
+		}
+		dimnames := [][]string{nil, {"left", "right"}}
+		if err := SetDimnames(*r, dimnames); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if out, _ := GetDimnames(*r); !reflect.DeepEqual(out, dimnames) {
+			t.Errorf("expected dimnames %v but got %v", dimnames, out)
+		}
+
+		class := []string{"special", "matrix"}
+		if err := SetClass(*r, class); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if out, _ := GetClass(*r); !reflect.DeepEqual(out, class) {
+			t.Errorf("expected class %v but got %v", class, out)
+		}
+
+		// R refuses dimensions that don't match the data when they're set directly
+		badDim, _ := IntegerToRSEXP([]int{5, 5})
+		if err := SetAttr(*r, "dim", ps.Protect(badDim)); !errors.Is(err, RError) {
+			t.Errorf("expected an R error but got %v", err)
+		}
+
+		// removing an attribute
+		if err := SetComment(*r, []string{"a note"}); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if err := SetComment(*r, nil); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if _, ok := GetAttr(*r, "comment"); ok {
+			t.Error("comment was not removed")
+		}
+
+		var attrNames []string
+		for _, attr := range Attributes(*r) {
+			attrNames = append(attrNames, attr.Name)
+		}
+		if len(attrNames) != 4 {
+			t.Errorf("expected 4 attributes but got %v", attrNames)
+		}
+	})
+}
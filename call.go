@@ -0,0 +1,99 @@
+package rgo
+
+/*
+#define USE_RINTERNALS
+#include <Rinternals.h>
+*/
+import "C"
+import (
+	"fmt"
+	"strings"
+)
+
+// IsFunction reports whether an RSEXP is an R function, which can be a closure written in R or a function built into R.
+func IsFunction(r RSEXP) bool {
+	switch TYPEOF(r) {
+	case CLOSXP, SPECIALSXP, BUILTINSXP:
+		return true
+	}
+	return false
+}
+
+// Call calls an R function with the given arguments, in the same way R would. The function is usually an argument
+// given to a Go function by R, like a user's objective function passed to an optimizer written in Go. If the input is
+// not an R function, a TypeMismatch error is returned.
+//
+// The arguments and the call itself are protected while the function runs, so they don't need to be protected by the
+// caller. The output, however, is not protected, so it needs to be protected if it's kept while anything else is
+// allocated in R.
+//
+// Calling an R function from Go is safe even if the function raises an error. R prints the error message as usual, and
+// Call returns an RError which includes the message.
+//
+// Like everything else in R, Call must run on R's main thread. That is the thread running a Go function called with
+// .Call, or a function given to embed.Do, but not a goroutine started from either of them.
+func Call(fn RSEXP, args ...*RSEXP) (*RSEXP, error) {
+	named := make([]NamedElement, len(args))
+	for i, arg := range args {
+		named[i].Value = *arg
+	}
+	return CallNamed(fn, named...)
+}
+
+// CallNamed is the same as Call, except that the arguments can have names, like calling f(x, na.rm = TRUE) in R. An
+// argument with an empty name is passed by position. Like Call, it must run on R's main thread.
+func CallNamed(fn RSEXP, args ...NamedElement) (*RSEXP, error) {
+	if !IsFunction(fn) {
+		return nil, TypeMismatch
+	}
+
+	var ps ProtectStack
+	defer ps.Unprotect()
+	for i := range args {
+		ps.Protect(&args[i].Value)
+	}
+
+	// a call in R is a pairlist, where the first element is the function and the rest are its arguments
+	call := ps.protect(C.allocList(C.int(len(args) + 1)))
+	C.SET_TYPEOF(call, C.LANGSXP)
+	C.SETCAR(call, fn)
+	node := C.CDR(call)
+	for _, arg := range args {
+		C.SETCAR(node, arg.Value)
+		if arg.Name != "" {
+			C.SET_TAG(node, installSymbol(arg.Name))
+		}
+		node = C.CDR(node)
+	}
+
+	return evalSafely(call, C.R_GlobalEnv)
+}
+
+// evalSafely evaluates an R expression in an environment. R_tryEval catches any R error, so that R doesn't jump out of
+// the Go code that called it, and reports it instead. If there is an error, an RError is returned with R's message.
+func evalSafely(expr, env C.SEXP) (*RSEXP, error) {
+	var errorOccurred C.int
+	result := C.R_tryEval(expr, env, &errorOccurred)
+	if errorOccurred != 0 {
+		return nil, fmt.Errorf("%w: %s", RError, lastErrorMessage())
+	}
+
+	out := RSEXP(result)
+	return &out, nil
+}
+
+// lastErrorMessage returns the message of the last error raised in R. R's API doesn't give access to the message
+// directly, so it's found the same way R code would, with geterrmessage. If that fails, the message is empty.
+func lastErrorMessage() string {
+	var ps ProtectStack
+	defer ps.Unprotect()
+
+	call := ps.protect(C.lang1(installSymbol("geterrmessage")))
+	var errorOccurred C.int
+	msg := C.R_tryEvalSilent(call, C.R_BaseEnv, &errorOccurred)
+	if errorOccurred != 0 || TYPEOF(RSEXP(msg)) != STRSXP || LENGTH(RSEXP(msg)) == 0 {
+		return ""
+	}
+	ps.protect(msg)
+	return strings.TrimSpace(charsxpToString(C.STRING_ELT(msg, 0)))
+}
@@ -0,0 +1,38 @@
+package rgo
+
+import "testing"
+
+func TestCall(t *testing.T) {
+	withR(t, func(t testing.TB, ps *ProtectStack) {
+		notAFunction := ps.Protect(NumericToRSEXP([]float64{1, 2, 3}))
+
+		if IsFunction(*notAFunction) {
+			t.Error("a numeric vector was mistaken for a function")
+		}
+		if _, err := Call(*notAFunction, notAFunction); err != TypeMismatch {
+			t.Errorf("expected a type mismatch but got %v", err)
+		}
+
+		// functions can come from R code as well as arguments
+		fns, err := Eval("sum\nfunction(x, scale = 1) x * scale", nil)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		sum, err := Call(*fns[0], notAFunction)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if out, _ := AsNumeric[float64](*sum); out[0] != 6 {
+			t.Errorf("expected a sum of 6 but got %v", out)
+		}
+
+		scale := ps.Protect(NumericToRSEXP([]float64{10}))
+		scaled, err := CallNamed(*fns[1], NamedElement{Value: *notAFunction}, NamedElement{Name: "scale", Value: *scale})
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if out, _ := AsNumeric[float64](*scaled); out[2] != 30 {
+			t.Errorf("expected the last element to be 30 but got %v", out)
+		}
+	})
+}
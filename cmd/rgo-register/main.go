@@ -0,0 +1,242 @@
+// Command rgo-register generates the code that registers a package's exported Go functions with R, so that its shared
+// library can be loaded with useDynLib(pkg, .registration = TRUE) and its functions called without looking them up by
+// name.
+//
+// It looks for every function in the package with an export comment that takes only C.SEXP arguments and returns a
+// C.SEXP, and writes a Go file which defines the R_init function R calls when it loads the library. The file registers
+// each function with R_registerRoutines, along with its number of arguments, and turns off R's lookup of symbols that
+// aren't registered with R_useDynamicSymbols. If the package imports rgo, the rgo_signal function is registered too.
+//
+// It's meant to be run with go generate, from a comment in the package:
+//
+//	//go:generate go run github.com/EMurray16/rgo/v2/cmd/rgo-register -name mypkg
+//
+// The name must be the name of the shared library without its extension (mypkg for mypkg.so), since that is what R
+// uses to find the R_init function. By default it is the name of the package's directory.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// rgoImportPath is the import path of the rgo package, which provides the rgo_signal function.
+const rgoImportPath = "github.com/EMurray16/rgo/v2"
+
+// routine is an exported Go function that can be called from R with .Call.
+type routine struct {
+	Name  string
+	Arity int
+}
+
+// pkgInfo is everything found in a package that's needed to generate its registration code.
+type pkgInfo struct {
+	Package   string
+	Routines  []routine
+	ImportRgo bool
+}
+
+func main() {
+	name := flag.String("name", "", "name of the shared library, without its extension (default: the package directory)")
+	output := flag.String("o", "rgo_init.go", "file to write, relative to the package directory")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	if err := run(dir, *name, *output); err != nil {
+		fmt.Fprintln(os.Stderr, "rgo-register:", err)
+		os.Exit(1)
+	}
+}
+
+// run finds the routines in the package in dir and writes its registration code to output.
+func run(dir, name, output string) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = filepath.Base(absDir)
+	}
+	outPath := filepath.Join(absDir, output)
+
+	info, skipped, err := findRoutines(absDir, filepath.Base(outPath))
+	if err != nil {
+		return err
+	}
+	for _, s := range skipped {
+		fmt.Fprintf(os.Stderr, "rgo-register: skipping %s, which doesn't only take and return C.SEXP\n", s)
+	}
+	if len(info.Routines) == 0 {
+		return fmt.Errorf("no exported functions found in %s", absDir)
+	}
+
+	src, err := generate(info, name)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outPath, src, 0o644)
+}
+
+// findRoutines parses the Go files in a directory, except for the generated file itself, and returns the exported
+// functions that can be called with .Call. It also returns the names of exported functions that can't be, because of
+// their arguments or return values.
+func findRoutines(dir, generated string) (info pkgInfo, skipped []string, err error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return fi.Name() != generated && !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return info, nil, err
+	}
+	if len(pkgs) != 1 {
+		return info, nil, fmt.Errorf("expected one package in %s but found %d", dir, len(pkgs))
+	}
+
+	for pkgName, pkg := range pkgs {
+		info.Package = pkgName
+		for _, file := range pkg.Files {
+			for _, imp := range file.Imports {
+				if path, _ := strconv.Unquote(imp.Path.Value); path == rgoImportPath {
+					info.ImportRgo = true
+				}
+			}
+
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv != nil || !isExported(fn) {
+					continue
+				}
+				arity, ok := callArity(fn.Type)
+				if !ok {
+					skipped = append(skipped, fn.Name.Name)
+					continue
+				}
+				info.Routines = append(info.Routines, routine{Name: fn.Name.Name, Arity: arity})
+			}
+		}
+	}
+
+	// files are read in no particular order, so sort for output that is the same every time
+	sort.Slice(info.Routines, func(i, j int) bool { return info.Routines[i].Name < info.Routines[j].Name })
+	sort.Strings(skipped)
+	return info, skipped, nil
+}
+
+// isExported reports whether a function has an export comment for cgo, which has to be exactly "//export Name".
+func isExported(fn *ast.FuncDecl) bool {
+	if fn.Doc == nil {
+		return false
+	}
+	for _, c := range fn.Doc.List {
+		if c.Text == "//export "+fn.Name.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// callArity returns the number of arguments of a function, if every argument is a C.SEXP and it returns a single
+// C.SEXP, which is what .Call needs.
+func callArity(ft *ast.FuncType) (int, bool) {
+	if ft.Results == nil || len(ft.Results.List) != 1 || len(ft.Results.List[0].Names) > 1 ||
+		!isSEXP(ft.Results.List[0].Type) {
+		return 0, false
+	}
+
+	arity := 0
+	for _, param := range ft.Params.List {
+		if !isSEXP(param.Type) {
+			return 0, false
+		}
+		// unnamed parameters, like func(C.SEXP), still count as one
+		if len(param.Names) == 0 {
+			arity++
+		}
+		arity += len(param.Names)
+	}
+	return arity, true
+}
+
+// isSEXP reports whether a type expression is C.SEXP.
+func isSEXP(expr ast.Expr) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == "C" && sel.Sel.Name == "SEXP"
+}
+
+var initTemplate = template.Must(template.New("init").Funcs(template.FuncMap{"params": params}).Parse(
+	`// Code generated by rgo-register. DO NOT EDIT.
+
+package {{.Package}}
+
+/*
+#include <Rinternals.h>
+#include <R_ext/Rdynload.h>
+{{range .Routines}}
+extern SEXP {{.Name}}({{params .Arity}});
+{{- end}}
+
+static const R_CallMethodDef callMethods[] = {
+{{- range .Routines}}
+	{"{{.Name}}", (DL_FUNC) &{{.Name}}, {{.Arity}}},
+{{- end}}
+	{NULL, NULL, 0}
+};
+
+void R_init_{{.Name}}(DllInfo *dll) {
+	R_registerRoutines(dll, NULL, callMethods, NULL, NULL);
+	R_useDynamicSymbols(dll, FALSE);
+}
+*/
+import "C"
+`))
+
+// generate writes the Go source of the registration code for a package.
+func generate(info pkgInfo, name string) ([]byte, error) {
+	routines := info.Routines
+	if info.ImportRgo {
+		routines = append([]routine{{Name: "rgo_signal", Arity: 1}}, routines...)
+	}
+
+	var buf bytes.Buffer
+	err := initTemplate.Execute(&buf, struct {
+		Package  string
+		Name     string
+		Routines []routine
+	}{info.Package, cIdentifier(name), routines})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// params returns the C parameter list of a function which takes the given number of SEXPs.
+func params(arity int) string {
+	if arity == 0 {
+		return "void"
+	}
+	return strings.TrimSuffix(strings.Repeat("SEXP, ", arity), ", ")
+}
+
+// cIdentifier makes a library name usable in the name of a C function. R does the same, replacing dots with
+// underscores, so the library mypkg.utils is initialized by R_init_mypkg_utils.
+func cIdentifier(name string) string {
+	return strings.ReplaceAll(name, ".", "_")
+}
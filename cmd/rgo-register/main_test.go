@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testSource = `package main
+
+// #include <Rinternals.h>
+import "C"
+import "github.com/EMurray16/rgo/v2"
+
+//export Double
+func Double(input C.SEXP) C.SEXP { return input }
+
+//export Add
+func Add(a, b C.SEXP) C.SEXP { return a }
+
+//export Constant
+func Constant() C.SEXP { return nil }
+
+//export Count
+func Count(n C.int) C.int { return n }
+
+// export NotExported
+func NotExported(input C.SEXP) C.SEXP { return input }
+
+func main() {}
+`
+
+func TestFindRoutines(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(testSource), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	info, skipped, err := findRoutines(dir, "rgo_init.go")
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+
+	want := []routine{{"Add", 2}, {"Constant", 0}, {"Double", 1}}
+	if !reflect.DeepEqual(info.Routines, want) {
+		t.Errorf("expected routines %v but got %v", want, info.Routines)
+	}
+	if !reflect.DeepEqual(skipped, []string{"Count"}) {
+		t.Errorf("expected to skip Count but skipped %v", skipped)
+	}
+	if !info.ImportRgo || info.Package != "main" {
+		t.Errorf("expected package main importing rgo but got %+v", info)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	info := pkgInfo{Package: "main", Routines: []routine{{"Add", 2}, {"Constant", 0}}, ImportRgo: true}
+	src, err := generate(info, "my.pkg")
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"extern SEXP Add(SEXP, SEXP);",
+		"extern SEXP Constant(void);",
+		`{"Add", (DL_FUNC) &Add, 2},`,
+		`{"rgo_signal", (DL_FUNC) &rgo_signal, 1},`,
+		"void R_init_my_pkg(DllInfo *dll) {",
+		"R_useDynamicSymbols(dll, FALSE);",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated code is missing %q:\n%s", want, src)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package rgo
+
+/*
+#define USE_RINTERNALS
+#include <Rinternals.h>
+*/
+import "C"
+
+// AsComplex extracts data from the input RSEXP and returns it as a slice of the given complex type. The data returned
+// is a copy of the data contained in the RSEXP that can be modified independently. If the underlying data is not a
+// complex vector, the TypeMismatch error is returned.
+func AsComplex[t RComplex](r RSEXP) (out []t, err error) {
+	if TYPEOF(r) != CPLXSXP {
+		return nil, TypeMismatch
+	}
+
+	view := complexView(r)
+	out = make([]t, len(view))
+	for i, c := range view {
+		out[i] = t(c)
+	}
+
+	return out, nil
+}
+
+// ComplexToRSEXP converts a slice of complex numbers into a C.SEXP, represented by the returned RSEXP data. The R
+// representation will have the same data as the input slice and be the CPLXSXP type (aka a complex in R).
+func ComplexToRSEXP[t RComplex](in []t) *RSEXP {
+	out := RSEXP(C.allocVector(C.CPLXSXP, C.long(len(in))))
+	view := complexView(out)
+	for i, c := range in {
+		view[i] = complex128(c)
+	}
+	return &out
+}
+
+// AsComplexMatrix reads a complex matrix from R, taking its number of rows and columns from its dim attribute. The
+// values are copied out of R, so the ComplexMatrix can be modified without changing the R object. Dimnames are not
+// read, because a ComplexMatrix has no names. If the data in the RSEXP is not complex, the TypeMismatch error is
+// returned.
+func AsComplexMatrix(r RSEXP) (out ComplexMatrix, err error) {
+	dataVec, err := AsComplex[complex128](r)
+	if err != nil {
+		return out, err
+	}
+
+	out.Data = dataVec
+	out.Nrow = int(C.nrows(r))
+	out.Ncol = int(C.ncols(r))
+
+	return out, nil
+}
+
+// ComplexMatrixToRSEXP converts a ComplexMatrix to a C.SEXP, represented by the returned RSEXP data. The R
+// representation will have the same data and dimensions as the input ComplexMatrix and be of the CPLXSXP type. If the
+// dimensions of the matrix don't match the length of its data, an ImpossibleMatrix error is returned.
+func ComplexMatrixToRSEXP(in ComplexMatrix) (*RSEXP, error) {
+	if !in.isSizeValid() {
+		return nil, ImpossibleMatrix
+	}
+
+	var ps ProtectStack
+	defer ps.Unprotect()
+
+	s := ps.Protect(ComplexToRSEXP(in.Data))
+	setDim(*s, in.Nrow, in.Ncol)
+
+	return s, nil
+}
@@ -0,0 +1,55 @@
+package rgo
+
+import "testing"
+
+func TestComplexMatrixToRSEXP_ImpossibleMatrix(t *testing.T) {
+	bad := ComplexMatrix{Nrow: 2, Ncol: 2, Data: []complex128{1 + 1i, 2 - 1i, 3}}
+	if _, err := ComplexMatrixToRSEXP(bad); err != ImpossibleMatrix {
+		t.Errorf("expected an impossible matrix error but got %v", err)
+	}
+}
+
+func TestComplexToRSEXP(t *testing.T) {
+	withR(t, func(t testing.TB, ps *ProtectStack) {
+		in := []complex64{1 + 2i, -3.5 + 0i, 0 - 1i}
+
+		r := ps.Protect(ComplexToRSEXP(in))
+
+		out, err := AsComplex[complex64](*r)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if len(out) != len(in) {
+			t.Fatalf("expected %v but got %v", in, out)
+		}
+		for i, c := range out {
+			if c != in[i] {
+				t.Errorf("expected %v but got %v", in, out)
+			}
+		}
+
+		// a complex vector isn't numeric, and vice versa
+		if _, err := AsNumeric[float64](*r); err != TypeMismatch {
+			t.Errorf("expected a type mismatch but got %v", err)
+		}
+		floats := ps.Protect(NumericToRSEXP([]float64{1}))
+		if _, err := AsComplex[complex128](*floats); err != TypeMismatch {
+			t.Errorf("expected a type mismatch but got %v", err)
+		}
+
+		// matrices should keep their dimensions
+		mat := ComplexMatrix{Nrow: 2, Ncol: 1, Data: []complex128{1i, 2}}
+		matSEXP, err := ComplexMatrixToRSEXP(mat)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ps.Protect(matSEXP)
+		outMat, err := AsComplexMatrix(*matSEXP)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if outMat.Nrow != 2 || outMat.Ncol != 1 || outMat.Data[0] != 1i {
+			t.Errorf("expected %v but got %v", mat, outMat)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package rgo
+
+/*
+#define USE_RINTERNALS
+#include <Rinternals.h>
+// rgo_signal is called from R on the output of a Go function. If the output is a condition made by Rgo, it is signaled
+// with stop, warning, or message, and otherwise the output is returned as it is. Signaling a condition can jump
+// straight back to R, so this has to happen here in C, after the Go function has already returned, rather than in Go.
+SEXP rgo_signal(SEXP x) {
+	if (!inherits(x, "rgo_condition")) {
+		return x;
+	}
+
+	const char *signal = "message";
+	if (inherits(x, "error")) {
+		signal = "stop";
+	} else if (inherits(x, "warning")) {
+		signal = "warning";
+	}
+
+	SEXP call = PROTECT(lang2(install(signal), x));
+	eval(call, R_BaseEnv);
+	UNPROTECT(1);
+
+	// warnings and messages carry the actual output of the function along with them
+	return VECTOR_ELT(x, 2);
+}
+*/
+import "C"
+import "errors"
+
+// conditionClasses maps each of Rgo's errors to the class of the R condition made from it, so R code can catch them
+// separately with tryCatch.
+var conditionClasses = []struct {
+	err   error
+	class string
+}{
+	{TypeMismatch, "rgo_type_mismatch"},
+	{UnsupportedType, "rgo_unsupported_type"},
+	{NotASEXP, "rgo_not_a_sexp"},
+	{ImpossibleMatrix, "rgo_impossible_matrix"},
+	{SizeMismatch, "rgo_size_mismatch"},
+	{InvalidIndex, "rgo_invalid_index"},
+	{IndexOutOfBounds, "rgo_index_out_of_bounds"},
+	{LengthMismatch, "rgo_length_mismatch"},
+	{IntegerOverflow, "rgo_integer_overflow"},
+	{VariableNotFound, "rgo_variable_not_found"},
+	{ReleasedPointer, "rgo_released_pointer"},
+	{RError, "rgo_r_error"},
+	{GoPanic, "rgo_panic"},
+}
+
+// ErrorCondition creates an R error condition from a Go error, which R raises as a real error that can be caught with
+// tryCatch. The condition's message is the text of the error, and if the error is (or wraps) one of Rgo's errors, like
+// TypeMismatch, the condition also has a matching class, like "rgo_type_mismatch". Every error condition also has the
+// "rgo_error" class.
+//
+// A Go function can't raise an R error itself, because R would jump straight out of it and leave the Go runtime in a
+// broken state. Instead, the Go function returns the condition, and R passes it through the rgo_signal function, which
+// raises it once the Go function has safely returned:
+//
+//	result <- .Call("rgo_signal", .Call("MyGoFunction", input))
+//
+// The output of any Go function can be passed through rgo_signal, since everything other than a condition made by Rgo
+// is returned unchanged.
+func ErrorCondition(err error) *RSEXP {
+	return makeCondition(err.Error(), errorClasses(err, "error"), nil)
+}
+
+// WarningCondition creates an R warning condition from a Go error. Unlike an error, a warning doesn't stop the function
+// from returning its output, so the value is returned by rgo_signal once the warning has been raised. A nil value
+// returns NULL. The condition has the same classes as an ErrorCondition, except that they end in "warning" instead.
+func WarningCondition(err error, value *RSEXP) *RSEXP {
+	return makeCondition(err.Error(), errorClasses(err, "warning"), value)
+}
+
+// MessageCondition creates an R message condition, which rgo_signal shows to the user (with message in R) before
+// returning the value. A nil value returns NULL.
+func MessageCondition(msg string, value *RSEXP) *RSEXP {
+	return makeCondition(msg, []string{"rgo_message", "rgo_condition", "message", "condition"}, value)
+}
+
+// errorClasses returns the classes of a condition made from a Go error, from most to least specific. The kind is
+// either "error" or "warning".
+func errorClasses(err error, kind string) []string {
+	var classes []string
+	for _, c := range conditionClasses {
+		if errors.Is(err, c.err) {
+			classes = append(classes, c.class)
+			break
+		}
+	}
+	return append(classes, "rgo_"+kind, "rgo_condition", kind, "condition")
+}
+
+// makeCondition creates an R condition, which is a list with a message and a call, along with the value that should be
+// returned after it is signaled.
+func makeCondition(msg string, classes []string, value *RSEXP) *RSEXP {
+	var ps ProtectStack
+	defer ps.Unprotect()
+
+	if value == nil {
+		value = rNull()
+	}
+	msgSEXP := ps.Protect(CharacterToRSEXP([]string{msg}))
+
+	// the names and elements always match, so there can't be an error
+	cond, _ := MakeNamedList([]string{"message", "call", "value"}, msgSEXP, rNull(), ps.Protect(value))
+	ps.Protect(cond)
+	SetClass(*cond, classes)
+	return cond
+}
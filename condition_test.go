@@ -0,0 +1,49 @@
+package rgo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestErrorClasses(t *testing.T) {
+	tests := []struct {
+		err  error
+		kind string
+		want []string
+	}{
+		{TypeMismatch, "error", []string{"rgo_type_mismatch", "rgo_error", "rgo_condition", "error", "condition"}},
+		{fmt.Errorf("%w: problem is names", LengthMismatch), "warning",
+			[]string{"rgo_length_mismatch", "rgo_warning", "rgo_condition", "warning", "condition"}},
+		{errors.New("something else"), "error", []string{"rgo_error", "rgo_condition", "error", "condition"}},
+	}
+
+	for _, test := range tests {
+		got := errorClasses(test.err, test.kind)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("expected classes %v for %v but got %v", test.want, test.err, got)
+		}
+	}
+}
+
+func TestErrorCondition(t *testing.T) {
+	withR(t, func(t testing.TB, ps *ProtectStack) {
+		cond := ps.Protect(ErrorCondition(fmt.Errorf("reading input: %w", TypeMismatch)))
+
+		elements, err := AsNamedList(*cond)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if len(elements) != 3 || elements[0].Name != "message" {
+			t.Fatalf("condition is not a list of message, call, and value: %v", elements)
+		}
+		msg, _ := AsCharacter[string](elements[0].Value)
+		if msg[0] != "reading input: "+TypeMismatch.Error() {
+			t.Errorf("condition has the wrong message: %v", msg)
+		}
+		if class, _ := GetClass(*cond); class[0] != "rgo_type_mismatch" {
+			t.Errorf("condition has the wrong class: %v", class)
+		}
+	})
+}
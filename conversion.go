@@ -2,11 +2,8 @@ package rgo
 
 /*
 #define USE_RINTERNALS
+#include <stdlib.h>
 #include <Rinternals.h>
-void doubleInsert(SEXP s, int index, double v) {
-	double *rp = REAL(s);
-	*(rp+index) = v;
-}
 void intInsert(SEXP s, int index, int v) {
 	int *ip = INTEGER(s);
 	*(ip+index) = v;
@@ -14,28 +11,18 @@ void intInsert(SEXP s, int index, int v) {
 void listInsert(SEXP s, int index, SEXP obj) {
 	SET_VECTOR_ELT(s, index, obj);
 }
-double doubleExtract(SEXP s, int index) {
-	double output;
-	//now get the pointer to the beginning of the vector in the SEXP
-	double *rp = REAL(s);
-	output = *(rp+index);
-	return output;
+SEXP listExtract(SEXP s, int index) {
+	return VECTOR_ELT(s, index);
 }
-int intExtract(SEXP s, int index) {
-	int output;
-	//get the pointer to the beginning of the vectors in the SEXP
-	int *ip = INTEGER(s);
-	output = *(ip+index);
-	return output;
+int isDataFrame(SEXP s) {
+	return inherits(s, "data.frame");
 }
-char charExtract(SEXP s, int index) {
-	char output;
-	const char *cp = CHAR(s);
-	output = *(cp+index);
-	return output;
+const char *charPointer(SEXP s) {
+	// CHAR is a macro, so we need a function around it for cgo
+	return CHAR(s);
 }
-void charInsert(SEXP s, int index, char* c) {
-	SET_STRING_ELT(s, index, mkChar(c));
+SEXP makeChar(const char *c, int len, int utf8) {
+	return mkCharLenCE(c, len, utf8 ? CE_UTF8 : CE_NATIVE);
 }
 
 // we use {SRCDIR} to make sure we can always find the R header files regardless of where this file is located
@@ -51,9 +38,11 @@ void charInsert(SEXP s, int index, char* c) {
 import "C"
 import (
 	"fmt"
+	"math"
 	"reflect"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 	"unsafe"
 )
 
@@ -129,7 +118,8 @@ func NewRSEXP(in any) (r RSEXP, err error) {
 
 	typeEnum := TYPEOF(r)
 	// Even if we have a C.SEXP, we still have no guarantee that the SEXP is of a type supported type
-	if !(typeEnum == REALSXP || typeEnum == INTSXP || typeEnum == STRSXP || typeEnum == CHARSXP) {
+	if !(typeEnum == REALSXP || typeEnum == INTSXP || typeEnum == LGLSXP || typeEnum == CPLXSXP || typeEnum == STRSXP ||
+		typeEnum == CHARSXP || typeEnum == VECSXP || typeEnum == RAWSXP || typeEnum == ENVSXP || typeEnum == EXTPTRSXP || IsFunction(r)) {
 		// fmt.Println(typeEnum)
 		return r, UnsupportedType
 	}
@@ -141,132 +131,551 @@ func NewRSEXP(in any) (r RSEXP, err error) {
 // AsNumeric extracts data from the input RSEXP and returns it as a slice of the given type parameter. The data is the
 // same data that is contained in the RSEXP, but a new copy that can be modified independently. If the underlying data
 // cannot be coerced into numeric data, the TypeMismatch error is returned.
+//
+// AsNumeric does not treat R's NA values specially. An NA integer is converted as-is from its underlying value (the
+// smallest 32 bit integer) and an NA double becomes a NaN. To tell missing values apart from real data, use AsNumericNA.
 func AsNumeric[t RNumeric](r RSEXP) (out []t, err error) {
-	//start by finding the length of the SEXP and making a slice
-	Slen := LENGTH(r)
-	out = make([]t, Slen)
-
 	rsexpType := TYPEOF(r)
 	if rsexpType != INTSXP && rsexpType != REALSXP {
 		return nil, TypeMismatch
 	}
 
-	// in order to determine the type (so that we call the right extraction function) we
-	// create an interface of type t we can perform a type switch on
-	for i := 0; i < Slen; i++ {
-		switch rsexpType {
-		case INTSXP:
-			out[i] = t(C.intExtract(r, C.int(i)))
-		case REALSXP:
-			out[i] = t(C.doubleExtract(r, C.int(i)))
-		}
+	// rather than calling into C for every element, read straight from a view of the underlying data
+	switch rsexpType {
+	case INTSXP:
+		out = convertSlice[t](integerView(r))
+	case REALSXP:
+		out = convertSlice[t](realView(r))
 	}
 
 	return out, nil
 }
 
-// AsMatrix returns a matrix based on the input RSEXP. All matrices must contain doubles/float64s with a dimension
-// attribute. The data returned by this function is a copy of the data contained in the RSEXP that can be modified
-// independently. If the data in the RSEXP cannot be coerced into a matrix, the TypeMismatch error is returned.
-func AsMatrix(r RSEXP) (out Matrix, err error) {
+// AsNumericNA is the same as AsNumeric, except that it also returns a companion slice of the same length which is true
+// wherever the R value is NA. Elements which are NA are always 0 in the data slice. Only R's NA is marked as missing,
+// so a NaN that is not NA (such as the result of 0/0) is kept as a NaN in the data slice.
+func AsNumericNA[t RNumeric](r RSEXP) (out []t, na []bool, err error) {
 	rsexpType := TYPEOF(r)
-	// technically we could accept integer matrices but for now we won't
-	if rsexpType != REALSXP {
-		return out, TypeMismatch
+	if rsexpType != INTSXP && rsexpType != REALSXP {
+		return nil, nil, TypeMismatch
+	}
+
+	Slen := LENGTH(r)
+	out = make([]t, Slen)
+	na = make([]bool, Slen)
+
+	switch rsexpType {
+	case INTSXP:
+		naInt := int32(C.R_NaInt)
+		for i, v := range integerView(r) {
+			if v == naInt {
+				na[i] = true
+				continue
+			}
+			out[i] = t(v)
+		}
+	case REALSXP:
+		out, na = splitReals[t](realView(r))
+	}
+
+	return out, na, nil
+}
+
+// AsLogical extracts data from the input RSEXP and returns it as a slice of bools. Because R's logicals can be TRUE,
+// FALSE, or NA, AsLogical also returns a companion slice of the same length which is true wherever the R value is NA.
+// Elements which are NA are always false in the data slice. The data returned is a copy of the data contained in the
+// RSEXP that can be modified independently. If the underlying data is not a logical vector, the TypeMismatch error is
+// returned.
+func AsLogical(r RSEXP) (out []bool, na []bool, err error) {
+	rsexpType := TYPEOF(r)
+	if rsexpType != LGLSXP {
+		return nil, nil, TypeMismatch
+	}
+
+	out, na = splitLogicals(logicalView(r))
+	return out, na, nil
+}
+
+// naInteger is R's NA_INTEGER, which is also NA_LOGICAL. R defines it as the smallest 32 bit integer, so it can be used
+// without a trip into C.
+const naInteger int32 = math.MinInt32
+
+// naRealBits is the bit pattern of R's NA_real_, which is a NaN with 1954 in its lower 32 bits.
+const naRealBits uint64 = 0x7FF00000000007A2
+
+// splitLogicals separates R logicals into their values and an NA mask. NA_LOGICAL is marked as missing and is false in
+// the values, and any other nonzero value is TRUE.
+func splitLogicals(lgls []int32) (out []bool, na []bool) {
+	out = make([]bool, len(lgls))
+	na = make([]bool, len(lgls))
+	for i, lgl := range lgls {
+		if lgl == naInteger {
+			na[i] = true
+			continue
+		}
+		out[i] = lgl != 0
 	}
-	dataVec, err := AsNumeric[float64](r)
+	return out, na
+}
+
+// joinLogicals is the reverse of splitLogicals, which turns values and an NA mask back into R logicals. A nil NA mask
+// means that no elements are NA.
+func joinLogicals(in []bool, na []bool) []int32 {
+	lgls := make([]int32, len(in))
+	for i, b := range in {
+		switch {
+		case na != nil && na[i]:
+			lgls[i] = naInteger
+		case b:
+			lgls[i] = 1
+		}
+	}
+	return lgls
+}
+
+// splitReals separates R doubles into their values and an NA mask. Only NA_real_ is marked as missing, and is 0 in the
+// values, so any other NaN is kept as a NaN.
+func splitReals[t RNumeric](vals []float64) (out []t, na []bool) {
+	out = make([]t, len(vals))
+	na = make([]bool, len(vals))
+	for i, v := range vals {
+		if isRealNA(v) {
+			na[i] = true
+			continue
+		}
+		out[i] = t(v)
+	}
+	return out, na
+}
+
+// joinReals is the reverse of splitReals, which turns values and an NA mask back into R doubles. A nil NA mask means
+// that no elements are NA.
+func joinReals[t RNumeric](in []t, na []bool) []float64 {
+	vals := make([]float64, len(in))
+	for i, num := range in {
+		if na != nil && na[i] {
+			vals[i] = math.Float64frombits(naRealBits)
+			continue
+		}
+		vals[i] = float64(num)
+	}
+	return vals
+}
+
+// convertSlice copies a slice of one numeric type into a new slice of another.
+func convertSlice[t, f RNumeric](in []f) []t {
+	out := make([]t, len(in))
+	for i, v := range in {
+		out[i] = t(v)
+	}
+	return out
+}
+
+// isRealNA reports whether a double is R's NA_real_. R's NA is a NaN with a payload of 1954 in its lower 32 bits, which
+// is what sets it apart from any other NaN. This is the same check R_IsNA does, without the trip into C.
+func isRealNA(f float64) bool {
+	return math.IsNaN(f) && uint32(math.Float64bits(f)) == 1954
+}
+
+// AsMatrix returns a matrix based on the input RSEXP. All matrices must contain numeric or logical data with a
+// dimension attribute, and always become float64s. The data returned by this function is a copy of the data contained
+// in the RSEXP that can be modified independently. If the data in the RSEXP cannot be coerced into a matrix, the
+// TypeMismatch error is returned.
+//
+// Integer and logical matrices are converted to doubles, and their NA values become R's NA_real_, so they survive a
+// trip back to R with MatrixToRSEXP. To keep their original type, use AsTypedMatrix.
+//
+// If the R matrix has dimnames, they are used as the matrix's row and column names.
+func AsMatrix(r RSEXP) (out Matrix, err error) {
+	typed, err := AsTypedMatrix[float64](r)
 	if err != nil {
 		return out, err
 	}
 
-	out.Data = dataVec
-	out.Nrow = int(C.nrows(r))
-	out.Ncol = int(C.ncols(r))
+	for i, isNA := range typed.NA {
+		if isNA {
+			typed.Data[i] = float64(C.R_NaReal)
+		}
+	}
 
-	return out, err
+	out.Nrow, out.Ncol = typed.Nrow, typed.Ncol
+	out.Data = typed.Data
+	out.RowNames, out.ColNames = typed.RowNames, typed.ColNames
+	return out, nil
 }
 
 // AsCharacter extracts the data from the input RSEXP and returns it as a slice of the given type parameter. The resulting
 // slice that contains the same data as the contents of the RSEXP, but a new copy that can be modified independently.
 // If the underlying data connot be coerced into string data, the TypeMismatch error is returned.
+//
+// R strings can be declared as UTF-8, latin1, bytes, or the native encoding. AsCharacter converts them all to UTF-8,
+// except for strings declared as bytes, which have no encoding and are returned as they are.
+//
+// NA values in R are returned as the string "NA", which can't be told apart from a real "NA" string. To tell missing
+// values apart from real data, use AsCharacterNA.
 func AsCharacter[t RCharacter](r RSEXP) (out []t, err error) {
-	//start by finding the length of the SEXP and making a slice
-	Slen := LENGTH(r)
-	out = make([]t, Slen)
+	out, na, err := AsCharacterNA[t](r)
+	if err != nil {
+		return nil, err
+	}
+
+	fillStringNA(out, na)
+	return out, nil
+}
 
+// fillStringNA replaces the elements marked as NA with the string "NA", since R's NA_STRING is a CHARSXP that contains
+// "NA". Elements that aren't marked are left alone, even if they are the string "NA" themselves.
+func fillStringNA[t RCharacter](out []t, na []bool) {
+	for i, isNA := range na {
+		if isNA {
+			out[i] = t("NA")
+		}
+	}
+}
+
+// AsCharacterNA is the same as AsCharacter, except that it also returns a companion slice of the same length which is
+// true wherever the R value is NA. Elements which are NA are always empty in the data slice.
+func AsCharacterNA[t RCharacter](r RSEXP) (out []t, na []bool, err error) {
 	rsexpType := TYPEOF(r)
 	if rsexpType != STRSXP {
-		return nil, TypeMismatch
+		return nil, nil, TypeMismatch
 	}
 
+	//start by finding the length of the SEXP and making a slice
+	Slen := LENGTH(r)
+	out = make([]t, Slen)
+	na = make([]bool, Slen)
+
 	for i := 0; i < Slen; i++ {
 		// first, pull out the CHARSXP of the string
 		charsxp := C.STRING_ELT(r, C.long(i))
 
-		// we want to build a string using each index of the character vector
-		// to do this we'll use a byte slice as the go-between (get it?)
-		nChar := LENGTH(RSEXP(charsxp))
-		goBytes := make([]byte, 0, nChar)
-		for charInd := 0; charInd < nChar; charInd++ {
-			// pull out the specific character and add it to the slice
-			indChar := C.charExtract(charsxp, C.int(charInd))
-			charAsByte := C.GoBytes(unsafe.Pointer(&indChar), 1)
-			// using append here is safer, in case we unexpectedly get more than 1 byte from charExtract
-			goBytes = append(goBytes, charAsByte...)
+		// NA_STRING is a single CHARSXP, so we can compare the pointers directly
+		if charsxp == C.R_NaString {
+			na[i] = true
+			continue
+		}
+
+		out[i] = t(charsxpToString(charsxp))
+	}
+
+	return out, na, nil
+}
+
+// AsList extracts the elements of an R list and returns them as a slice of RSEXPs, in the same order as the list. Each
+// element is the same SEXP that is in the list rather than a copy, so its data can be extracted with the other AsX
+// functions. If the input RSEXP is not a list, the TypeMismatch error is returned.
+//
+// The elements are protected from R's garbage collector for as long as the list itself is, which for arguments to
+// .Call is until the Go function returns.
+func AsList(r RSEXP) (out []RSEXP, err error) {
+	if TYPEOF(r) != VECSXP {
+		return nil, TypeMismatch
+	}
+
+	Slen := LENGTH(r)
+	out = make([]RSEXP, Slen)
+	for i := 0; i < Slen; i++ {
+		out[i] = RSEXP(C.listExtract(r, C.int(i)))
+	}
+
+	return out, nil
+}
+
+// NamedElement is a single element of a named list, as returned by AsNamedList.
+type NamedElement struct {
+	Name  string
+	Value RSEXP
+}
+
+// AsNamedList is the same as AsList, except that it also returns the name of each element. The elements are returned in
+// the same order as the list, so duplicate names (which R allows) are kept. If the list has no names at all, every
+// name is an empty string. If the input RSEXP is not a list, the TypeMismatch error is returned.
+func AsNamedList(r RSEXP) (out []NamedElement, err error) {
+	elements, err := AsList(r)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := GetNames(r)
+	if err != nil {
+		return nil, err
+	}
+
+	out = make([]NamedElement, len(elements))
+	for i, elem := range elements {
+		out[i].Value = elem
+		if names != nil {
+			out[i].Name = names[i]
 		}
-		// convert the assembled slice to a string
-		out[i] = t(goBytes)
 	}
 
 	return out, nil
 }
 
+// charsxpToString copies the contents of a CHARSXP into a Go string, which is always UTF-8 when R knows the encoding.
+// The whole string is copied in one call using the CHARSXP's length, rather than one byte at a time.
+//
+// Strings in latin1 are converted to UTF-8. Strings in the native encoding are assumed to be UTF-8 already, which is
+// true of nearly every modern R installation, and are otherwise translated by R. Strings declared as "bytes" have no
+// encoding at all, so their bytes are returned untouched.
+func charsxpToString(charsxp C.SEXP) string {
+	nChar := LENGTH(RSEXP(charsxp))
+	str := C.GoStringN(C.charPointer(charsxp), C.int(nChar))
+
+	switch C.getCharCE(charsxp) {
+	case C.CE_LATIN1:
+		return latin1ToUTF8(str)
+	case C.CE_NATIVE:
+		if !utf8.ValidString(str) {
+			// R knows what the native encoding is, so let it do the translation
+			return C.GoString(C.translateCharUTF8(charsxp))
+		}
+	}
+
+	// UTF-8, bytes, and valid native strings need no conversion
+	return str
+}
+
+// latin1ToUTF8 converts a latin1 (ISO-8859-1) string to UTF-8. Every latin1 byte is the code point of the same value,
+// so each byte maps directly onto a rune.
+func latin1ToUTF8(in string) string {
+	// plain ASCII is identical in both encodings, so there's no need to allocate
+	isASCII := true
+	for i := 0; i < len(in); i++ {
+		if in[i] >= utf8.RuneSelf {
+			isASCII = false
+			break
+		}
+	}
+	if isASCII {
+		return in
+	}
+
+	var sb strings.Builder
+	sb.Grow(2 * len(in))
+	for i := 0; i < len(in); i++ {
+		sb.WriteRune(rune(in[i]))
+	}
+	return sb.String()
+}
+
 // NumericToRSEXP converts a slice of numeric data into a C.SEXP, represented by the returned RSEXP data. The R
 // representation will have the same data as the input slice and be the REALSXP type (aka a double in R). Because
 // the intent of this function is to prepare data to be sent back to R, which largely treats doubles and integers the
-// same, this function cannot return an RSEXP of type INTSXP.
+// same, this function cannot return an RSEXP of type INTSXP. When R needs a true integer vector, use IntegerToRSEXP.
 func NumericToRSEXP[t RNumeric](in []t) *RSEXP {
+	// without an NA mask the lengths always match, so there can't be an error
+	out, _ := NumericToRSEXPNA(in, nil)
+	return out
+}
+
+// NumericToRSEXPNA is the same as NumericToRSEXP, except that it also takes a companion slice which specifies which
+// elements should be NA in R. This mirrors the output of AsNumericNA, so that numeric data from R can be sent back
+// without losing its missing values. A nil NA slice means that no elements are NA. Otherwise, if the data and NA
+// slices are not the same length, a LengthMismatch error is returned.
+func NumericToRSEXPNA[t RNumeric](in []t, na []bool) (*RSEXP, error) {
+	if na != nil && len(in) != len(na) {
+		return nil, LengthMismatch
+	}
+
+	out := RSEXP(C.allocVector(C.REALSXP, C.long(len(in))))
+	copy(realView(out), joinReals(in, na))
+	return &out, nil
+}
+
+// IntegerToRSEXP converts a slice of numeric data into a C.SEXP, represented by the returned RSEXP data. Unlike
+// NumericToRSEXP, the R representation will be the INTSXP type (aka an integer in R), which is useful for IDs, counts,
+// and anything else that R code expects to be identical() to an integer. Float inputs are truncated towards zero, the
+// same way as a Go conversion to an int.
+//
+// R integers are 32 bits, and the smallest 32 bit integer is reserved for NA. If any element of the input is outside
+// of that range, or is a NaN or infinite float, no RSEXP is created and an IntegerOverflow error is returned.
+func IntegerToRSEXP[t RNumeric](in []t) (*RSEXP, error) {
+	return IntegerToRSEXPNA(in, nil)
+}
+
+// IntegerToRSEXPNA is the same as IntegerToRSEXP, except that it also takes a companion slice which specifies which
+// elements should be NA in R. Elements marked as NA are not checked for overflow. A nil NA slice means that no
+// elements are NA. Otherwise, if the data and NA slices are not the same length, a LengthMismatch error is returned.
+func IntegerToRSEXPNA[t RNumeric](in []t, na []bool) (*RSEXP, error) {
+	if na != nil && len(in) != len(na) {
+		return nil, LengthMismatch
+	}
+
+	// check the whole slice before allocating anything, so we never hand back a half filled vector
+	for i, num := range in {
+		if na != nil && na[i] {
+			continue
+		}
+		if !fitsRInteger(num) {
+			return nil, fmt.Errorf("%w: element %d is %v", IntegerOverflow, i, num)
+		}
+	}
+
 	size := len(in)
-	s := C.allocVector(C.REALSXP, C.long(size))
+	s := C.allocVector(C.INTSXP, C.long(size))
 
 	for i, num := range in {
-		C.doubleInsert(s, C.int(i), C.double(float64(num)))
+		if na != nil && na[i] {
+			C.intInsert(s, C.int(i), C.R_NaInt)
+			continue
+		}
+		C.intInsert(s, C.int(i), C.int(num))
 	}
 
 	out := RSEXP(s)
-	return &out
+	return &out, nil
+}
+
+// fitsRInteger checks whether a number can be stored as an R integer without overflowing or colliding with NA.
+func fitsRInteger[t RNumeric](num t) bool {
+	// every RNumeric type converts to a float64 without crossing the int32 boundaries, so one check covers them all
+	f := float64(num)
+	if math.IsNaN(f) {
+		return false
+	}
+	return f > math.MinInt32 && f < math.MaxInt32+1
+}
+
+// wholeNumber converts element i of a double vector to an int64, making sure nothing is lost on the way. A fraction or
+// a NaN returns a TypeMismatch error, and a number that doesn't fit in an int64, including an infinity, returns an
+// IntegerOverflow error.
+func wholeNumber(i int, f float64) (int64, error) {
+	// a NaN is never equal to itself, so this catches them too
+	if f != math.Trunc(f) {
+		return 0, fmt.Errorf("%w: element %d is %v, which is not a whole number", TypeMismatch, i, f)
+	}
+	if f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("%w: element %d is %v, which doesn't fit in an int64", IntegerOverflow, i, f)
+	}
+	return int64(f), nil
+}
+
+// LogicalToRSEXP converts a slice of bools into a C.SEXP, represented by the returned RSEXP data. The R
+// representation will have the same data as the input slice and be the LGLSXP type (aka a logical in R). Because a
+// Go bool can't be missing, the output never contains NA values. To send NA values back to R, use LogicalToRSEXPNA.
+func LogicalToRSEXP(in []bool) *RSEXP {
+	// without an NA mask the lengths always match, so there can't be an error
+	out, _ := LogicalToRSEXPNA(in, nil)
+	return out
+}
+
+// LogicalToRSEXPNA is the same as LogicalToRSEXP, except that it also takes a companion slice which specifies which
+// elements should be NA in R. This mirrors the output of AsLogical, so that logical vectors from R can be sent back
+// without losing their missing values. A nil NA slice means that no elements are NA. Otherwise, if the data and NA
+// slices are not the same length, a LengthMismatch error is returned.
+func LogicalToRSEXPNA(in []bool, na []bool) (*RSEXP, error) {
+	if na != nil && len(in) != len(na) {
+		return nil, LengthMismatch
+	}
+
+	out := RSEXP(C.allocVector(C.LGLSXP, C.long(len(in))))
+	copy(logicalView(out), joinLogicals(in, na))
+	return &out, nil
 }
 
 // MatrixToRSEXP converts a Matrix a C.SEXP, represented by the returned RSEXP data. The R representation
-// will have the same data and dimensions as the input Matrix and be of the REALSXP type (aka a double in R).
-func MatrixToRSEXP(in Matrix) *RSEXP {
-	s := NumericToRSEXP(in.Data)
+// will have the same data and dimensions as the input Matrix and be of the REALSXP type (aka a double in R). The
+// matrix's row and column names are sent to R as its dimnames, so that they survive a round trip through AsMatrix.
+//
+// If the matrix's dimensions don't match the length of its data, an ImpossibleMatrix error is returned. If its names
+// don't match its dimensions, a LengthMismatch error is returned.
+func MatrixToRSEXP(in Matrix) (*RSEXP, error) {
+	if !in.isSizeValid() {
+		return nil, ImpossibleMatrix
+	}
+	if !in.areNamesValid() {
+		return nil, fmt.Errorf("%w: problem is number of names vs. matrix dimensions", LengthMismatch)
+	}
+
+	var ps ProtectStack
+	defer ps.Unprotect()
 
-	dimSEXP := NumericToRSEXP([]int{in.Nrow, in.Ncol})
+	s := ps.Protect(NumericToRSEXP(in.Data))
+	setDim(*s, in.Nrow, in.Ncol)
 
-	C.setAttrib(*s, C.R_DimSymbol, *dimSEXP)
+	if in.RowNames != nil || in.ColNames != nil {
+		if err := SetDimnames(*s, [][]string{in.RowNames, in.ColNames}); err != nil {
+			return nil, err
+		}
+	}
 
-	return s
+	return s, nil
+}
+
+// setDim sets the dim attribute of a vector, which makes it a matrix in R. The vector should already be protected.
+func setDim(s RSEXP, nrow, ncol int) {
+	// R stores dimensions as integers, and R itself won't allow a dimension that overflows one, so skip the error check
+	dimSEXP, _ := IntegerToRSEXP([]int{nrow, ncol})
+	C.setAttrib(C.SEXP(s), C.R_DimSymbol, C.SEXP(*dimSEXP))
 }
 
 // CharacterToRSEXP converts a slice of strings (or byte slices) into a C.SEXP, represented by the returned RSEXP
 // data. The R representation will have the same data as the input slice and be the STRSXP type (aka the character type
 // in R).
 func CharacterToRSEXP[t RCharacter](in []t) *RSEXP {
+	// without an NA mask the lengths always match, so there can't be an error
+	out, _ := CharacterToRSEXPNA(in, nil)
+	return out
+}
+
+// CharacterToRSEXPNA is the same as CharacterToRSEXP, except that it also takes a companion slice which specifies which
+// elements should be NA in R. This mirrors the output of AsCharacterNA, so that character data from R can be sent back
+// without losing its missing values. A nil NA slice means that no elements are NA. Otherwise, if the data and NA
+// slices are not the same length, a LengthMismatch error is returned.
+func CharacterToRSEXPNA[t RCharacter](in []t, na []bool) (*RSEXP, error) {
+	if na != nil && len(in) != len(na) {
+		return nil, LengthMismatch
+	}
+
+	var ps ProtectStack
+	defer ps.Unprotect()
+
+	// every CHARSXP we create is an allocation, so the vector needs to be protected while we fill it
 	size := len(in)
-	s := C.allocVector(C.STRSXP, C.long(size))
+	s := ps.protect(C.allocVector(C.STRSXP, C.long(size)))
 
 	for i, str := range in {
-		C.charInsert(s, C.int(i), C.CString(string(str)))
+		if na != nil && na[i] {
+			C.SET_STRING_ELT(s, C.long(i), C.R_NaString)
+			continue
+		}
+		C.SET_STRING_ELT(s, C.long(i), stringToCharsxp(string(str)))
 	}
 
 	out := RSEXP(s)
-	return &out
+	return &out, nil
+}
+
+// stringToCharsxp creates a CHARSXP from a Go string. Valid UTF-8 strings are marked as UTF-8, so that R knows how to
+// read them regardless of the locale. Anything else is left in the native encoding, as R would do itself.
+//
+// R's strings can't contain NUL bytes, and creating one that does is an R error, so the string is cut off at the first
+// NUL byte just like it would be as a C string.
+func stringToCharsxp(str string) C.SEXP {
+	if nul := strings.IndexByte(str, 0); nul >= 0 {
+		str = str[:nul]
+	}
+
+	var isUTF8 C.int
+	if utf8.ValidString(str) {
+		isUTF8 = 1
+	}
+
+	// R copies the string into its own memory, so the C copy can be freed right away
+	cstr := C.CString(str)
+	defer C.free(unsafe.Pointer(cstr))
+	return C.makeChar(cstr, C.int(len(str)), isUTF8)
 }
 
 // MakeList creates an R list from the provided inputs and returns its representing RSEXP object. Unlike MakeDataFrame
 // and MakeNamedList, there are no restrictions on the data that is provided.
 func MakeList(in ...*RSEXP) *RSEXP {
+	var ps ProtectStack
+	defer ps.Unprotect()
+	ps.protectAll(in)
+
 	//start by making the list vector itself
 	s := C.allocVector(C.VECSXP, C.long(len(in)))
 
@@ -289,8 +698,12 @@ func MakeNamedList(names []string, data ...*RSEXP) (*RSEXP, error) {
 		return nil, LengthMismatch
 	}
 
+	var ps ProtectStack
+	defer ps.Unprotect()
+	ps.protectAll(data)
+
 	//start by making the list vector itself
-	s := C.allocVector(C.VECSXP, C.long(len(data)))
+	s := ps.protect(C.allocVector(C.VECSXP, C.long(len(data))))
 
 	//now insert the objects into the list SEXP
 	for ind, obj := range data {
@@ -298,7 +711,7 @@ func MakeNamedList(names []string, data ...*RSEXP) (*RSEXP, error) {
 	}
 
 	// set the names attribute
-	nameSEXP := CharacterToRSEXP(names)
+	nameSEXP := ps.Protect(CharacterToRSEXP(names))
 	C.setAttrib(s, C.R_NamesSymbol, *nameSEXP)
 
 	// wrap the result and return
@@ -319,7 +732,8 @@ func MakeNamedList(names []string, data ...*RSEXP) (*RSEXP, error) {
 //
 // MakeDataFrame also checks that the types of all the provided data columns are valid types according to Rgo and
 // that they can be used to create a column in a data frame. If these conditions are not met, an UnsupportedType error
-// will be returned. Right now, this list includes integer vectors, real vectors, and string vectors only.
+// will be returned. Right now, this list includes integer vectors, real vectors, logical vectors, and string vectors
+// only. Factors are integer vectors under the hood, so factors made with FactorToRSEXP are also valid columns.
 // Examples of invalid types include lists, data frames, or other nested SEXP objects.
 func MakeDataFrame(rowNames, colNames []string, dataColumns ...*RSEXP) (*RSEXP, error) {
 	// first, check to make sure the number of column names and number of columns match
@@ -333,8 +747,7 @@ func MakeDataFrame(rowNames, colNames []string, dataColumns ...*RSEXP) (*RSEXP,
 		if LENGTH(*dataCol) != nRowsProvided {
 			return nil, fmt.Errorf("%w: problem is length of provided dataColumns columns", LengthMismatch)
 		}
-		colType := TYPEOF(*dataCol)
-		if colType != INTSXP && colType != REALSXP && colType != STRSXP {
+		if !isDataFrameColumn(*dataCol) {
 			return nil, UnsupportedType
 		}
 	}
@@ -356,22 +769,27 @@ func MakeDataFrame(rowNames, colNames []string, dataColumns ...*RSEXP) (*RSEXP,
 	if autoRownames {
 		rowNames = make([]string, nRowsProvided)
 		for i := 0; i < int(nRowsProvided); i++ {
-			rowNames[i] = strconv.Itoa(i)
+			rowNames[i] = strconv.Itoa(i + 1)
 		}
 	}
 
+	var ps ProtectStack
+	defer ps.Unprotect()
+	ps.protectAll(dataColumns)
+
 	// under the hood, a dataColumns frame is just a list with attributes. Make the list first
-	r := C.allocVector(C.VECSXP, C.long(len(dataColumns)))
+	r := ps.protect(C.allocVector(C.VECSXP, C.long(len(dataColumns))))
 	for ind, obj := range dataColumns {
 		C.listInsert(r, C.int(ind), *obj)
 	}
 
 	// in order to set the attributes, we need the row and column names to be SEXPs
-	rowSEXP := CharacterToRSEXP(rowNames)
-	colSEXP := CharacterToRSEXP(colNames)
+	rowSEXP := ps.Protect(CharacterToRSEXP(rowNames))
+	colSEXP := ps.Protect(CharacterToRSEXP(colNames))
+	classSEXP := ps.Protect(CharacterToRSEXP([]string{"data.frame"}))
 
 	// now we set the attributes
-	C.setAttrib(r, C.R_ClassSymbol, C.ScalarString(C.mkChar(C.CString("data.frame"))))
+	C.setAttrib(r, C.R_ClassSymbol, *classSEXP)
 	C.setAttrib(r, C.R_RowNamesSymbol, *rowSEXP)
 	C.setAttrib(r, C.R_NamesSymbol, *colSEXP)
 
@@ -379,6 +797,118 @@ func MakeDataFrame(rowNames, colNames []string, dataColumns ...*RSEXP) (*RSEXP,
 	return &out, nil
 }
 
+// DataFrame is the Go representation of an R data frame, as returned by AsDataFrame. The columns are in the same order
+// as the column names, and each column is the same SEXP that is in the data frame rather than a copy. The data in each
+// column can be extracted using the AsX function that matches its type, which can be found using TYPEOF. Factor
+// columns have the INTSXP type, and can be read with AsFactor.
+type DataFrame struct {
+	RowNames []string
+	ColNames []string
+	Columns  []RSEXP
+}
+
+// Nrow returns the number of rows in the data frame.
+func (df *DataFrame) Nrow() int {
+	return len(df.RowNames)
+}
+
+// Ncol returns the number of columns in the data frame.
+func (df *DataFrame) Ncol() int {
+	return len(df.Columns)
+}
+
+// Column returns the column of the data frame with the provided name. If there is no column with that name, an
+// IndexOutOfBounds error is returned. If there are several columns with the same name, the first one is returned.
+func (df *DataFrame) Column(name string) (RSEXP, error) {
+	for i, colName := range df.ColNames {
+		if colName == name {
+			return df.Columns[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no column named %q", IndexOutOfBounds, name)
+}
+
+// AsDataFrame reads an R data frame into a DataFrame. It is the reverse of MakeDataFrame, and holds the input to the
+// same standards. If the input is not a list with the data.frame class, the TypeMismatch error is returned. If any
+// column is not one of the types MakeDataFrame accepts, an UnsupportedType error is returned. If any column doesn't
+// have the same length as the number of rows, a LengthMismatch error is returned.
+//
+// Row names are always returned as strings. When R created the row names automatically, they are the row numbers,
+// starting at 1 to be consistent with R.
+func AsDataFrame(r RSEXP) (out DataFrame, err error) {
+	if TYPEOF(r) != VECSXP || C.isDataFrame(r) == 0 {
+		return out, TypeMismatch
+	}
+
+	out.Columns, err = AsList(r)
+	if err != nil {
+		return out, err
+	}
+
+	out.ColNames, err = GetNames(r)
+	if err != nil {
+		return out, err
+	}
+	if len(out.ColNames) != len(out.Columns) {
+		return out, fmt.Errorf("%w: problem is number of column names vs. columns", LengthMismatch)
+	}
+
+	out.RowNames, err = rowNamesOf(r)
+	if err != nil {
+		return out, err
+	}
+
+	for _, col := range out.Columns {
+		if LENGTH(col) != len(out.RowNames) {
+			return out, fmt.Errorf("%w: problem is length of columns vs. number of rows", LengthMismatch)
+		}
+		if !isDataFrameColumn(col) {
+			return out, UnsupportedType
+		}
+	}
+
+	return out, nil
+}
+
+// rowNamesOf returns the row names of a data frame as strings. R stores automatic row names in the compact form
+// c(NA, -nrow), which R usually expands into 1:nrow before we see it. Both forms, as well as explicit integer and
+// character row names, are turned into strings.
+func rowNamesOf(r RSEXP) ([]string, error) {
+	rowSEXP := RSEXP(C.getAttrib(r, C.R_RowNamesSymbol))
+	switch TYPEOF(rowSEXP) {
+	case STRSXP:
+		return AsCharacter[string](rowSEXP)
+	case INTSXP:
+		ints, na, err := AsNumericNA[int](rowSEXP)
+		if err != nil {
+			return nil, err
+		}
+		// the compact form is NA followed by the number of rows, which is negative when the row names are automatic
+		if len(ints) == 2 && na[0] {
+			nrow := ints[1]
+			if nrow < 0 {
+				nrow = -nrow
+			}
+			ints = make([]int, nrow)
+			for i := range ints {
+				ints[i] = i + 1
+			}
+		}
+		rowNames := make([]string, len(ints))
+		for i, v := range ints {
+			rowNames[i] = strconv.Itoa(v)
+		}
+		return rowNames, nil
+	}
+	return nil, fmt.Errorf("%w: row names must be integers or strings", UnsupportedType)
+}
+
+// isDataFrameColumn checks whether an RSEXP is one of the types that Rgo supports as a data frame column.
+func isDataFrameColumn(r RSEXP) bool {
+	colType := TYPEOF(r)
+	return colType == INTSXP || colType == REALSXP || colType == LGLSXP || colType == STRSXP
+}
+
 // ExportRSEXP converts an input RSEXP object into the caller's provided C.SEXP type. It is used as a final function to
 // prepare data to be sent back to R. The input type is any, because the rsexp package cannot anticipate the strict
 // C.SEXP type used by the caller. Like NewRSEXP, ExportRSEXP checks the type parameter provided using reflection and returns
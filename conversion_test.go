@@ -1,3 +1,391 @@
 package rgo
 
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"unsafe"
 
+	"github.com/EMurray16/rgo/v2/internal/bytewise"
+)
+
+func TestFitsRInteger(t *testing.T) {
+	// ints inside R's range should fit, including the largest and the smallest non-NA values
+	goodInts := []int64{0, 1, -1, math.MaxInt32, math.MinInt32 + 1}
+	for _, v := range goodInts {
+		if !fitsRInteger(v) {
+			t.Errorf("expected %d to fit in an R integer but it didn't", v)
+		}
+	}
+
+	// the smallest int32 is NA in R, and anything outside of int32 overflows
+	badInts := []int64{math.MinInt32, math.MaxInt32 + 1, math.MinInt32 - 1, math.MaxInt64}
+	for _, v := range badInts {
+		if fitsRInteger(v) {
+			t.Errorf("expected %d to overflow an R integer but it didn't", v)
+		}
+	}
+
+	// floats are truncated, so anything that truncates into the range is fine
+	goodFloats := []float64{0, 3.14, -2.71, math.MaxInt32 + 0.5, math.MinInt32 + 0.5}
+	for _, v := range goodFloats {
+		if !fitsRInteger(v) {
+			t.Errorf("expected %v to fit in an R integer but it didn't", v)
+		}
+	}
+
+	badFloats := []float64{math.NaN(), math.Inf(1), math.Inf(-1), math.MinInt32, math.MaxInt32 + 1}
+	for _, v := range badFloats {
+		if fitsRInteger(v) {
+			t.Errorf("expected %v to overflow an R integer but it didn't", v)
+		}
+	}
+
+	// smaller types can never overflow
+	if !fitsRInteger(int8(-128)) || !fitsRInteger(int16(32767)) || !fitsRInteger(float32(1e9)) {
+		t.Error("expected small types to always fit in an R integer")
+	}
+}
+
+func TestWholeNumber(t *testing.T) {
+	tests := []struct {
+		in      float64
+		out     int64
+		wantErr error
+	}{
+		{0, 0, nil},
+		{-42, -42, nil},
+		{1 << 53, 1 << 53, nil},
+		{2.5, 0, TypeMismatch},
+		{-0.1, 0, TypeMismatch},
+		{math.NaN(), 0, TypeMismatch},
+		{math.Inf(1), 0, IntegerOverflow},
+		{math.Inf(-1), 0, IntegerOverflow},
+		{1 << 63, 0, IntegerOverflow},
+	}
+	for _, test := range tests {
+		out, err := wholeNumber(0, test.in)
+		if !errors.Is(err, test.wantErr) {
+			t.Errorf("%v: expected error %v but got %v", test.in, test.wantErr, err)
+		}
+		if err == nil && out != test.out {
+			t.Errorf("%v: expected %d but got %d", test.in, test.out, out)
+		}
+	}
+}
+
+func TestConvertSlice(t *testing.T) {
+	in := []int32{-2, 0, 3}
+	out := convertSlice[float64](in)
+	expected := []float64{-2, 0, 3}
+	if len(out) != len(expected) {
+		t.Fatalf("expected %v but got %v", expected, out)
+	}
+	for i, v := range out {
+		if v != expected[i] {
+			t.Errorf("expected %v but got %v", expected, out)
+		}
+	}
+
+	// the output is a copy, so changing it shouldn't change the input
+	out[0] = 42
+	if in[0] != -2 {
+		t.Error("changing the converted slice changed the input")
+	}
+}
+
+func TestLatin1ToUTF8(t *testing.T) {
+	// ASCII is the same in both encodings
+	if out := latin1ToUTF8("hello"); out != "hello" {
+		t.Errorf("expected ASCII to be unchanged, but got %q", out)
+	}
+
+	// "café" and "Ø" in latin1 are single bytes above 0x7f
+	latin1 := string([]byte{'c', 'a', 'f', 0xe9, ' ', 0xd8})
+	expected := "café Ø"
+	if out := latin1ToUTF8(latin1); out != expected {
+		t.Errorf("expected %q but got %q", expected, out)
+	}
+
+	if out := latin1ToUTF8(""); out != "" {
+		t.Errorf("expected an empty string but got %q", out)
+	}
+}
+
+// makeBenchmarkStrings creates a character vector in R for the AsCharacter benchmarks.
+func makeBenchmarkStrings(ps *ProtectStack) RSEXP {
+	strs := make([]string, 10000)
+	for i := range strs {
+		strs[i] = fmt.Sprintf("row %d of the benchmark data, with café for good measure", i)
+	}
+	return *ps.Protect(CharacterToRSEXP(strs))
+}
+
+func BenchmarkAsCharacter(b *testing.B) {
+	withR(b, func(tb testing.TB, ps *ProtectStack) {
+		r := makeBenchmarkStrings(ps)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := AsCharacter[string](r); err != nil {
+				tb.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkAsCharacterBytewise(b *testing.B) {
+	withR(b, func(tb testing.TB, ps *ProtectStack) {
+		r := makeBenchmarkStrings(ps)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := bytewise.AsCharacter(unsafe.Pointer(r)); err != nil {
+				tb.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestAsNamedList(t *testing.T) {
+	withR(t, func(t testing.TB, ps *ProtectStack) {
+		a := ps.Protect(NumericToRSEXP([]float64{1.1, 2.2}))
+		b := ps.Protect(CharacterToRSEXP([]string{"x"}))
+
+		// a list without names should have empty names
+		list := ps.Protect(MakeList(a, b))
+		elems, err := AsNamedList(*list)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if len(elems) != 2 || elems[0].Name != "" || elems[1].Name != "" {
+			t.Errorf("expected 2 unnamed elements but got %v", elems)
+		}
+
+		named, err := MakeNamedList([]string{"a", "b"}, a, b)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ps.Protect(named)
+		elems, err = AsNamedList(*named)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if len(elems) != 2 || elems[0].Name != "a" || elems[1].Name != "b" {
+			t.Errorf("expected elements named a and b but got %v", elems)
+		}
+		floats, err := AsNumeric[float64](elems[0].Value)
+		if err != nil || len(floats) != 2 || floats[1] != 2.2 {
+			t.Errorf("expected to get the first element back but got %v and %v", floats, err)
+		}
+
+		// a vector isn't a list
+		if _, err := AsList(*a); err != TypeMismatch {
+			t.Errorf("expected a type mismatch but got %v", err)
+		}
+	})
+}
+
+func TestAsDataFrame(t *testing.T) {
+	withR(t, func(t testing.TB, ps *ProtectStack) {
+		a := ps.Protect(NumericToRSEXP([]float64{1.1, 2.2, 3.3}))
+		b := ps.Protect(CharacterToRSEXP([]string{"a", "b", "c"}))
+
+		// automatic row names are the row numbers, starting at 1 like in R
+		df, err := MakeDataFrame(nil, []string{"num", "chr"}, a, b)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ps.Protect(df)
+		out, err := AsDataFrame(*df)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if out.Nrow() != 3 || out.Ncol() != 2 {
+			t.Errorf("expected a 3 x 2 data frame but got %d x %d", out.Nrow(), out.Ncol())
+		}
+		if out.RowNames[0] != "1" || out.RowNames[2] != "3" {
+			t.Errorf("expected row names 1 to 3 but got %v", out.RowNames)
+		}
+		col, err := out.Column("chr")
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if strs, _ := AsCharacter[string](col); len(strs) != 3 || strs[1] != "b" {
+			t.Errorf("expected the chr column but got %v", strs)
+		}
+		if _, err := out.Column("nope"); !errors.Is(err, IndexOutOfBounds) {
+			t.Errorf("expected an index out of bounds error but got %v", err)
+		}
+
+		// explicit row names should come back as they are
+		df, err = MakeDataFrame([]string{"x", "y", "z"}, []string{"num", "chr"}, a, b)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ps.Protect(df)
+		out, err = AsDataFrame(*df)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if out.RowNames[0] != "x" || out.RowNames[2] != "z" {
+			t.Errorf("expected row names x to z but got %v", out.RowNames)
+		}
+
+		// a plain list isn't a data frame
+		list := ps.Protect(MakeList(a, b))
+		if _, err := AsDataFrame(*list); err != TypeMismatch {
+			t.Errorf("expected a type mismatch but got %v", err)
+		}
+	})
+}
+
+func TestMatrixToRSEXP_Invalid(t *testing.T) {
+	// the size and names are checked before anything is sent to R
+	bad := Matrix{Nrow: 2, Ncol: 2, Data: []float64{1, 2, 3}}
+	if _, err := MatrixToRSEXP(bad); err != ImpossibleMatrix {
+		t.Errorf("expected an impossible matrix error but got %v", err)
+	}
+
+	badNames := CopyMatrix(startingMatrix)
+	badNames.RowNames = []string{"a"}
+	if _, err := MatrixToRSEXP(badNames); !errors.Is(err, LengthMismatch) {
+		t.Errorf("expected a length mismatch but got %v", err)
+	}
+}
+
+func TestMatrixDimnames(t *testing.T) {
+	withR(t, func(t testing.TB, ps *ProtectStack) {
+		in := CopyMatrix(startingMatrix)
+		in.SetNames([]string{"a", "b", "c"}, []string{"x", "y"})
+
+		r, err := MatrixToRSEXP(in)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ps.Protect(r)
+
+		out, err := AsMatrix(*r)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(in, out) {
+			t.Errorf("expected %v but got %v", in, out)
+		}
+
+		// names that don't fit are an error
+		in.ColNames = []string{"x"}
+		if _, err := MatrixToRSEXP(in); !errors.Is(err, LengthMismatch) {
+			t.Errorf("expected a length mismatch but got %v", err)
+		}
+
+		// a matrix without names doesn't get dimnames
+		r, err = MatrixToRSEXP(startingMatrix)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ps.Protect(r)
+		if dimnames, _ := GetDimnames(*r); dimnames != nil {
+			t.Errorf("expected no dimnames but got %v", dimnames)
+		}
+	})
+}
+
+func TestLogicalNA(t *testing.T) {
+	// R logicals are TRUE, FALSE, or NA, and anything nonzero other than NA counts as TRUE
+	tests := []struct {
+		lgl       int32
+		val, isNA bool
+	}{
+		{1, true, false},
+		{0, false, false},
+		{naInteger, false, true},
+		{-1, true, false},
+		{2, true, false},
+	}
+	for _, test := range tests {
+		out, na := splitLogicals([]int32{test.lgl})
+		if out[0] != test.val || na[0] != test.isNA {
+			t.Errorf("%d: expected value %v and NA %v but got %v and %v", test.lgl, test.val, test.isNA, out[0], na[0])
+		}
+	}
+
+	// going back to R, the NA mask wins over the value, and TRUE is always stored as 1
+	in := []bool{true, false, true, false}
+	na := []bool{false, false, true, true}
+	expected := []int32{1, 0, naInteger, naInteger}
+	if lgls := joinLogicals(in, na); !reflect.DeepEqual(lgls, expected) {
+		t.Errorf("expected %v but got %v", expected, lgls)
+	}
+	if lgls := joinLogicals(in, nil); !reflect.DeepEqual(lgls, []int32{1, 0, 1, 0}) {
+		t.Errorf("expected no NAs without a mask but got %v", lgls)
+	}
+
+	// a round trip keeps all three states
+	out, outNA := splitLogicals(joinLogicals(in, na))
+	if !reflect.DeepEqual(out, []bool{true, false, false, false}) || !reflect.DeepEqual(outNA, na) {
+		t.Errorf("round trip gave values %v and NA %v", out, outNA)
+	}
+}
+
+func TestRealNA(t *testing.T) {
+	// R's NA_real_ is a NaN with 1954 in the lower word, and other NaNs and regular numbers are not NA
+	naReal := math.Float64frombits(0x7FF00000000007A2)
+	tests := []struct {
+		name string
+		in   float64
+		isNA bool
+	}{
+		{"NA_real_", naReal, true},
+		{"NaN", math.NaN(), false},
+		{"NaN with another payload", math.Float64frombits(0x7FF00000000007A3), false},
+		{"infinity", math.Inf(1), false},
+		{"negative infinity", math.Inf(-1), false},
+		{"zero", 0, false},
+		{"NA's payload as a number", 1954, false},
+		{"number", 19.54, false},
+	}
+	for _, test := range tests {
+		if isRealNA(test.in) != test.isNA {
+			t.Errorf("%s: expected isRealNA to be %v for bits %x", test.name, test.isNA, math.Float64bits(test.in))
+		}
+
+		out, na := splitReals[float64]([]float64{test.in})
+		if na[0] != test.isNA {
+			t.Errorf("%s: expected NA to be %v but got %v", test.name, test.isNA, na[0])
+		}
+		switch {
+		case test.isNA && out[0] != 0:
+			t.Errorf("%s: expected NA to be 0 in the data but got %v", test.name, out[0])
+		case !test.isNA && math.IsNaN(test.in) && !math.IsNaN(out[0]):
+			t.Errorf("%s: expected NaN to be kept but got %v", test.name, out[0])
+		case !test.isNA && !math.IsNaN(test.in) && out[0] != test.in:
+			t.Errorf("%s: expected %v but got %v", test.name, test.in, out[0])
+		}
+	}
+
+	// going back to R, only masked elements become NA_real_, and a NaN in the data stays an ordinary NaN
+	vals := joinReals([]float64{1.5, math.NaN(), 0}, []bool{false, false, true})
+	if vals[0] != 1.5 {
+		t.Errorf("expected 1.5 but got %v", vals[0])
+	}
+	if !math.IsNaN(vals[1]) || isRealNA(vals[1]) {
+		t.Errorf("expected an ordinary NaN but got bits %x", math.Float64bits(vals[1]))
+	}
+	if math.Float64bits(vals[2]) != naRealBits {
+		t.Errorf("expected NA_real_ but got bits %x", math.Float64bits(vals[2]))
+	}
+	if vals := joinReals([]int{3}, nil); vals[0] != 3 {
+		t.Errorf("expected no NAs without a mask but got %v", vals)
+	}
+}
+
+func TestFillStringNA(t *testing.T) {
+	// only masked elements become "NA", and strings that look like missing values are left alone
+	out := []string{"NA", "NaN", "", "x"}
+	fillStringNA(out, []bool{false, false, true, false})
+	expected := []string{"NA", "NaN", "NA", "x"}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("expected %v but got %v", expected, out)
+	}
+}
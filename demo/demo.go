@@ -16,25 +16,25 @@ import "C"
 
 import (
 	"fmt"
-	"github.com/EMurray16/rgo"
+	"github.com/EMurray16/rgo/v2"
 )
 
+// returnError sends a Go error back to R as an error condition, which R raises by calling rgo_signal on the output
+func returnError(err error) C.SEXP {
+	out, _ := rgo.ExportRSEXP[C.SEXP](rgo.ErrorCondition(err))
+	return out
+}
+
 //export TestFloat
 func TestFloat(s C.SEXP) C.SEXP {
 	point, err := rgo.NewRSEXP(s)
 	if err != nil {
-		// return the error as a string
-		outString := rgo.CharacterToRSEXP([]string{err.Error()})
-		out, _ := rgo.ExportRSEXP[C.SEXP](outString)
-		return out
+		return returnError(err)
 	}
 
 	TestSlicef, err := rgo.AsNumeric[float64](point)
 	if err != nil {
-		// return the error as a string
-		outString := rgo.CharacterToRSEXP([]string{err.Error()})
-		out, _ := rgo.ExportRSEXP[C.SEXP](outString)
-		return out
+		return returnError(err)
 	}
 
 	//now multiply everything in the slice by two
@@ -53,10 +53,7 @@ func TestFloat(s C.SEXP) C.SEXP {
 	out, err := rgo.ExportRSEXP[C.SEXP](s2)
 	fmt.Println(out, err)
 	if err != nil {
-		// return the error as a string
-		outString := rgo.CharacterToRSEXP([]string{err.Error()})
-		out, _ := rgo.ExportRSEXP[C.SEXP](outString)
-		return out
+		return returnError(err)
 	}
 	//defereference the pointer
 	return out
@@ -67,19 +64,13 @@ func TestInt(s C.SEXP) C.SEXP {
 	//make an unsafe pointer to the SEXP
 	point, err := rgo.NewRSEXP(s)
 	if err != nil {
-		// return the error as a string
-		outString := rgo.CharacterToRSEXP([]string{err.Error()})
-		out, _ := rgo.ExportRSEXP[C.SEXP](outString)
-		return out
+		return returnError(err)
 	}
 
 	TestSlice, err := rgo.AsNumeric[int](point)
 	fmt.Println(TestSlice)
 	if err != nil {
-		// return the error as a string
-		outString := rgo.CharacterToRSEXP([]string{err.Error()})
-		out, _ := rgo.ExportRSEXP[C.SEXP](outString)
-		return out
+		return returnError(err)
 	}
 
 	//now multiply everything in the slice by two
@@ -94,10 +85,7 @@ func TestInt(s C.SEXP) C.SEXP {
 	s2 := rgo.NumericToRSEXP(TestSlice)
 	out, err := rgo.ExportRSEXP[C.SEXP](s2)
 	if err != nil {
-		// return the error as a string
-		outString := rgo.CharacterToRSEXP([]string{err.Error()})
-		out, _ := rgo.ExportRSEXP[C.SEXP](outString)
-		return out
+		return returnError(err)
 	}
 	//defereference the pointer
 	return out
@@ -108,19 +96,13 @@ func TestString(s C.SEXP) C.SEXP {
 	//make an unsafe pointer to the SEXP
 	point, err := rgo.NewRSEXP(s)
 	if err != nil {
-		// return the error as a string
-		outString := rgo.CharacterToRSEXP([]string{err.Error()})
-		out, _ := rgo.ExportRSEXP[C.SEXP](outString)
-		return out
+		return returnError(err)
 	}
 
 	//now get the string and write a file
 	TestString, err := rgo.AsCharacter[string](point)
 	if err != nil {
-		// return the error as a string
-		outString := rgo.CharacterToRSEXP([]string{err.Error()})
-		out, _ := rgo.ExportRSEXP[C.SEXP](outString)
-		return out
+		return returnError(err)
 	}
 
 	//now send a message back to R
@@ -137,10 +119,7 @@ func TestString(s C.SEXP) C.SEXP {
 	s2 := rgo.CharacterToRSEXP([][]byte{[]byte(outstring), []byte(outstring + "again")})
 	out, err := rgo.ExportRSEXP[C.SEXP](s2)
 	if err != nil {
-		// return the error as a string
-		outString := rgo.CharacterToRSEXP([]string{err.Error()})
-		out, _ := rgo.ExportRSEXP[C.SEXP](outString)
-		return out
+		return returnError(err)
 	}
 	//defereference the pointer
 	return out
@@ -150,18 +129,12 @@ func TestString(s C.SEXP) C.SEXP {
 func TestMatrix(s C.SEXP) C.SEXP {
 	vecPoint, err := rgo.NewRSEXP(s)
 	if err != nil {
-		// return the error as a string
-		outString := rgo.CharacterToRSEXP([]string{err.Error()})
-		out, _ := rgo.ExportRSEXP[C.SEXP](outString)
-		return out
+		return returnError(err)
 	}
 
 	TestMat, err := rgo.AsMatrix(vecPoint)
 	if err != nil {
-		// return the error as a string
-		outString := rgo.CharacterToRSEXP([]string{err.Error()})
-		out, _ := rgo.ExportRSEXP[C.SEXP](outString)
-		return out
+		return returnError(err)
 	}
 	fmt.Println(TestMat)
 
@@ -170,10 +143,7 @@ func TestMatrix(s C.SEXP) C.SEXP {
 	for i := 0; i < TestMat.Nrow; i++ {
 		row, err := TestMat.GetRow(i)
 		if err != nil {
-			// return the error as a string
-			outString := rgo.CharacterToRSEXP([]string{err.Error()})
-			out, _ := rgo.ExportRSEXP[C.SEXP](outString)
-			return out
+			return returnError(err)
 		}
 		var sum float64
 		for _, f := range row {
@@ -183,10 +153,7 @@ func TestMatrix(s C.SEXP) C.SEXP {
 	}
 	err = TestMat.AppendCol(addedcol)
 	if err != nil {
-		// return the error as a string
-		outString := rgo.CharacterToRSEXP([]string{err.Error()})
-		out, _ := rgo.ExportRSEXP[C.SEXP](outString)
-		return out
+		return returnError(err)
 	}
 
 	//now append the columns
@@ -194,10 +161,7 @@ func TestMatrix(s C.SEXP) C.SEXP {
 	for i := 0; i < TestMat.Ncol; i++ {
 		col, err := TestMat.GetCol(i)
 		if err != nil {
-			// return the error as a string
-			outString := rgo.CharacterToRSEXP([]string{err.Error()})
-			out, _ := rgo.ExportRSEXP[C.SEXP](outString)
-			return out
+			return returnError(err)
 		}
 		var sum float64
 		for _, f := range col {
@@ -207,20 +171,17 @@ func TestMatrix(s C.SEXP) C.SEXP {
 	}
 	err = TestMat.AppendRow(addedrow)
 	if err != nil {
-		// return the error as a string
-		outString := rgo.CharacterToRSEXP([]string{err.Error()})
-		out, _ := rgo.ExportRSEXP[C.SEXP](outString)
-		return out
+		return returnError(err)
 	}
 
 	//convert the matrix back to a slice
-	s2 := rgo.MatrixToRSEXP(TestMat)
+	s2, err := rgo.MatrixToRSEXP(TestMat)
+	if err != nil {
+		return returnError(err)
+	}
 	out, err := rgo.ExportRSEXP[C.SEXP](s2)
 	if err != nil {
-		// return the error as a string
-		outString := rgo.CharacterToRSEXP([]string{err.Error()})
-		out, _ := rgo.ExportRSEXP[C.SEXP](outString)
-		return out
+		return returnError(err)
 	}
 	return out
 }
@@ -239,10 +200,7 @@ func MakeDataFrame() C.SEXP {
 	df, err := rgo.MakeDataFrame(rowNames, colNames, col1SEXP, col2SEXP)
 	fmt.Println("made DataFrame:", df)
 	if err != nil {
-		// return the error as a string
-		outString := rgo.CharacterToRSEXP([]string{err.Error()})
-		out, _ := rgo.ExportRSEXP[C.SEXP](outString)
-		return out
+		return returnError(err)
 	}
 
 	out, _ := rgo.ExportRSEXP[C.SEXP](df)
@@ -263,10 +221,7 @@ func MakeNamedList() C.SEXP {
 	df, err := rgo.MakeNamedList(colNames, col1SEXP, col2SEXP)
 	fmt.Println("made named list:", df)
 	if err != nil {
-		// return the error as a string
-		outString := rgo.CharacterToRSEXP([]string{err.Error()})
-		out, _ := rgo.ExportRSEXP[C.SEXP](outString)
-		return out
+		return returnError(err)
 	}
 
 	out, _ := rgo.ExportRSEXP[C.SEXP](df)
@@ -17,40 +17,103 @@ Rgo uses R's internal functions to extract data from a SEXP and into a Go typed
 these objects can be found in R's documentation at https://cran.r-project.org/doc/manuals/r-release/R-ints.html#SEXPs.
 In short, everything in R is a SEXP, which is a pointer to a SEXPREC, which in turn contains some header information,
 attributes, and a pointer to the data itself. A SEXP can point to a SEXPREC of up to a couple dozen types which map
-to R's types. Rgo only concerns itself with 5 of them:
+to R's types. Rgo only concerns itself with 13 of them:
 
     1. REALSXP, akin to a Go slice of float64s and, when containing the dimension attributes, a matrix.
     2. INTSXP, akin to a Go slice of integers
-    3. CHARSXP, akin to a a Go string
-    4. STRSXP, akin to a Go slice of strings
-    5. VECSXP, which is an R list and, when containing the correct attributes, data frame
+    3. LGLSXP, akin to a Go slice of bools, except that it can also contain NA values
+    4. CPLXSXP, akin to a Go slice of complex128s
+    5. CHARSXP, akin to a a Go string
+    6. STRSXP, akin to a Go slice of strings
+    7. VECSXP, which is an R list and, when containing the correct attributes, data frame
+    8. RAWSXP, akin to a Go byte slice of binary data
+    9. CLOSXP, an R function written in R, which can be called from Go
+    10. BUILTINSXP, an R function built into R, which can be called from Go
+    11. SPECIALSXP, a built in R function that gets its arguments unevaluated, which can also be called from Go
+    12. ENVSXP, an R environment, which holds variables by name
+    13. EXTPTRSXP, an external pointer, which Rgo uses to keep Go values alive in R
 
 In C, the type of data a SEXP points to can be found using the ''TYPEOF'' function. It returns an integer, which can
 be matched to the relevant types based on the constant enumerations declared in this package. As a convenience, Rgo's
 TYPEOF function wraps R's TYPEOF function. Rgo's LENGTH function also wraps R's LENGTH (also called LENGTH) function.
 
-Rgo contains generic function which can be used to extract data from a SEXP as a desired type in Go. There are three
-functions, which relate to R's numeric type, character type, and matrix type. The numeric and character functions use
-a type parameter as an input so that the Go slice can be made to be the supported type which is most convenient for the
-caller, within reason. These functions are:
+Rgo contains generic function which can be used to extract data from a SEXP as a desired type in Go. There are four
+functions, which relate to R's numeric type, character type, logical type, and matrix type. The numeric and character
+functions use a type parameter as an input so that the Go slice can be made to be the supported type which is most
+convenient for the caller, within reason. These functions are:
 
     1. func AsNumeric[t RNumeric](r RSEXP) ([]t, error)
-    1. func AsCharacter[t RCharacter](r RSEXP) ([]t, error)
+    2. func AsCharacter[t RCharacter](r RSEXP) ([]t, error)
+    3. func AsLogical(r RSEXP) ([]bool, []bool, error)
     4. func AsMatrix(r RSEXP) (Matrix, error)
 
+Factors are integer vectors with a levels attribute, so AsNumeric only returns their codes. AsFactor returns the codes
+and the levels together as a Factor, and AsFactorValues decodes them into a slice of strings. Factors can be sent back to
+R with FactorToRSEXP, including as a column of a data frame.
+
+Lists can be read with AsList, which returns each element as its own RSEXP, or AsNamedList, which also returns the
+name of each element in order. This allows a single argument from R to bundle several inputs together.
+
+Data frames can be read with AsDataFrame, which checks that the input really is a data frame and returns its row names,
+column names, and columns. Like MakeDataFrame, it returns a LengthMismatch error if the columns don't line up and an
+UnsupportedType error if any column is not a type Rgo supports.
+
+Rather than reading each column separately, a data frame can also be read straight into a slice of structs using
+UnmarshalDataFrame, and a slice of structs can be sent to R as a data frame using MarshalDataFrame. Each field is a
+column, named by its rgo struct tag:
+
+    type Record struct {
+        ID    int       `rgo:"id"`
+        Value float64   `rgo:"value"`
+        When  time.Time `rgo:"when"`
+    }
+
+Raw vectors, which hold binary data like serialized objects, can be extracted with AsRaw or viewed without a copy
+using RawView. Unlike character vectors, they can contain NUL bytes. They are sent back to R with RawToRSEXP.
+
+A Matrix can carry row and column names, which AsMatrix reads from the dimnames of an R matrix and MatrixToRSEXP
+writes back, returning a LengthMismatch error if they don't fit the matrix. Names can be set with the SetNames method,
+which checks the same thing.
+
+AsMatrix always returns float64s, converting integer and logical matrices. To keep the type of the matrix, use
+AsTypedMatrix, which returns a TypedMatrix of float64s, ints, or bools along with which elements are NA. It is sent
+back to R with TypedMatrixToRSEXP.
+
+Arrays with any number of dimensions are extracted with AsArray, which returns an Array in R's column-major order,
+and sent back with ArrayToRSEXP. The Slice method returns the sub-array at one index along any dimension.
+
+Complex vectors can be extracted with AsComplex, which takes an RComplex type parameter, and complex matrices with
+AsComplexMatrix. They are sent back to R with ComplexToRSEXP and ComplexMatrixToRSEXP.
+
+Because a logical in R can be TRUE, FALSE, or NA, AsLogical returns a second slice which marks the elements that are NA.
+The numeric and character functions have NA-aware variants, AsNumericNA and AsCharacterNA, which do the same.
+
 Each of these functions checks the SEXPTYPE of the underlying SEXP and will return an error if it doesn't match the
 function that was called.
 
+For large vectors, RealView and IntegerView return a slice which points directly at R's memory instead of a copy. A
+view is only valid while R keeps the underlying object alive (for .Call arguments, until the Go function returns), and
+writing to it modifies the R object in place. AsReal and AsInteger make a copy of the same data in one bulk copy.
+
 Sending data from Go to R
 
 Sending data from Go to R is done by creating an RSEXP (which will always point to a newly created C.SEXP) from one
 of the supported Go types:
 
     1. func NumericToRSEXP[t RNumeric](in []t) *RSEXP
-    2. func CharacterToRSEXP[t RCharacter](in []t) *RSEXP
-    3. func MatrixToRSEXP(in Matrix) *RSEXP
+    2. func IntegerToRSEXP[t RNumeric](in []t) (*RSEXP, error)
+    3. func CharacterToRSEXP[t RCharacter](in []t) *RSEXP
+    4. func LogicalToRSEXP(in []bool) *RSEXP
+    5. func MatrixToRSEXP(in Matrix) (*RSEXP, error)
+
+NumericToRSEXP always creates a double vector in R, while IntegerToRSEXP creates an integer vector. Because R's integers
+are 32 bits (with the smallest value reserved for NA), IntegerToRSEXP returns an IntegerOverflow error if any element
+doesn't fit.
 
-This SEXPTYPE of the output SEXP from these functions will match the R internal type which makes the most sense.
+This SEXPTYPE of the output SEXP from these functions will match the R internal type which makes the most sense. Each
+of the vector functions also has an NA variant (like NumericToRSEXPNA) which takes a second slice marking which elements
+should be NA, so that missing data can make the round trip from R to Go and back. MatrixToRSEXP has no NA variant, since
+a Matrix keeps NA as R's NA_real_ in its data, and a TypedMatrix carries its own NA mask for TypedMatrixToRSEXP.
 
 Because R does not allow functions to have multiple returns, the preferred way to return multiple pieces of data
 from a function is a list. Therefore, Rgo contains functions to create three types of lists: a generic list,
@@ -60,10 +123,67 @@ a named list, and a data frame.
     2. func MakeNamedList(names []string, data ...*RSEXP) (*RSEXP, error)
     3. func MakeDataFrame(rowNames, colNames []string, dataColumns ...*RSEXP) (*RSEXP, error)
 
+Named lists can also be made directly from a Go map with string keys or a struct using MarshalList, and read back using
+UnmarshalList. Nested maps and structs become nested lists, struct fields are named using rgo struct tags, and map
+elements are sorted by key so the result is always the same.
+
 The functions to create named lists and data frames enforce data quality so that valid R objects can be created. This
 includes enforcing the number of names and objects provided, checking the lengths of all the columns provided in a data
 frame, and making sure no nested objects (like lists or data frames themselves) are provided as columns for data frames.
 
+Attributes, like names, dimensions, and classes, can be read and set on any RSEXP with GetAttr, SetAttr, and
+Attributes. The common ones have typed helpers, like GetNames and SetNames, GetDim and SetDim, GetDimnames and
+SetDimnames, GetClass and SetClass, and GetComment and SetComment, which check that the attribute fits the object. If R
+itself refuses to set an attribute, SetAttr returns an RError instead of crashing the R session.
+
+Errors in Go can be sent back to R as conditions, using ErrorCondition, WarningCondition, and MessageCondition. A Go
+function can't raise an R error directly, because R would jump out of the function without letting Go clean up. Instead,
+the Go function returns the condition, and R passes the output through rgo_signal, which raises it after Go has returned:
+
+    result <- .Call("rgo_signal", .Call("MyGoFunction", input))
+
+Conditions made from Rgo's errors have a matching class, like rgo_type_mismatch or rgo_size_mismatch, along with
+rgo_error, so they can be handled separately with tryCatch.
+
+R functions can be passed to Go functions like any other argument, and called from Go with Call, or CallNamed for
+named arguments. The function runs with R_tryEval, so an error in R is returned as an RError instead of jumping out of
+the Go function. Like every call into R, it has to happen on R's main thread, and not in a goroutine:
+
+    value, err := rgo.Call(objective, ps.Protect(rgo.NumericToRSEXP(params)))
+
+R code can also be run from Go with Eval, which parses a string of R code and evaluates each expression, returning
+their results. If the code can't be parsed, the error is a *ParseError with the line and column of the problem:
+
+    results, err := rgo.Eval("quantile(x, 0.9)", env)
+
+Environments can be passed to Go as well, and their variables are used with Get, Assign, Exists, and Ls, which work
+like the R functions of the same names. GlobalEnv and BaseEnv return R's global and base environments.
+
+Go values that need to live between calls, like a loaded model or a database connection, can be sent to R in an
+external pointer with ExternalPtrToRSEXP. When R passes the pointer back, AsExternalPtr returns the value, checking
+that it has the expected type. The value is released once R garbage collects the pointer, or sooner with
+ReleaseExternalPtr.
+
+A panic in an exported Go function kills the whole R session. Guard runs the body of a function, recovering any panic
+and turning it into an error condition with the rgo_panic class and the Go stack trace. Errors returned by the body
+become error conditions too, so a Guard is all an exported function needs for its error handling:
+
+    out := rgo.Guard(func() (*rgo.RSEXP, error) {
+        ...
+    })
+
+CatchPanic does the same recovery for a function that only returns an error, giving back an error that wraps GoPanic.
+
+R's garbage collector can run any time R allocates memory, and every RSEXP made in Go starts out unprotected from it.
+Rgo protects everything it allocates while it builds an object, but an RSEXP that is kept around while another one is
+created (like the elements of a list) needs to be protected by the caller. The ProtectStack type does this:
+
+    var ps rgo.ProtectStack
+    defer ps.Unprotect()
+    a := ps.Protect(rgo.NumericToRSEXP(floats))
+    b := ps.Protect(rgo.CharacterToRSEXP(strs))
+    list := rgo.MakeList(a, b)
+
 In order to send data back to R, it must be a C.SEXP that matches the user's notion of a SEXP, not Rgo's. Therefore, Rgo
 provides a generic ExportRSEXP function which is used to create a user's C.SEXP that has the same data as the RSEXP that
 has been made using the rgo library. Callers provide a type parameter, which should always be their C.SEXP:
@@ -72,6 +192,11 @@ has been made using the rgo library. Callers provide a type parameter, which sho
 
 If their provided type is not a C.SEXP (or a *C.SEXP which is also acceptable) an error is returned.
 
+Embedding R in Go
+
+Rgo can also be used the other way around, running R inside a Go program. The embed subpackage starts R on a dedicated
+OS thread and runs R code and rgo functions on it. See the documentation of that package for details.
+
 Building Your Package and Calling Functions in R
 
 In order for a package of Go functions to be callable from R, they must take any number of C.SEXP objects as input and
@@ -90,6 +215,11 @@ Finally, the Go functions can be called in R using the .Call function:
 
     output = .Call("DoubleVector", input)
 
+R packages should register their functions with R rather than have R look them up by name. The rgo-register command
+generates the R_init function that does this, so the library can be loaded with useDynLib(pkg, .registration = TRUE):
+
+    //go:generate go run github.com/EMurray16/rgo/v2/cmd/rgo-register -name <libName>
+
 For a more complete demonstration, see the example below, or the demo package at https://github.com/EMurray16/rgo/demo.
 
 Example
@@ -117,15 +247,13 @@ The code below contains a functional example of a Go function that can be called
         // cast the incoming SEXP as a GoSEXP
         r, err := rgo.NewRSEXP(&input)
         if err != nil {
-            fmt.Println(err)
-            return nil
+            return returnError(err)
         }
 
         // create a slice from the SEXPs data
         floats, err := rgo.AsNumeric[float64](r)
         if err != nil {
-            fmt.Println(err)
-            return nil
+            return returnError(err)
         }
 
         // double each element of the slice
@@ -138,17 +266,23 @@ The code below contains a functional example of a Go function that can be called
 
 		mySEXP, err := rgo.ExportRSEXP[C.SEXP](outputSEXP)
 		if err != nil {
-            fmt.Println(err)
-            return nil
+            return returnError(err)
         }
 
         return mySEXP
     }
 
-Once it is compiled to a shared library, the function can be called using R's .Call() interface:
+    // returnError sends a Go error back to R as an error condition
+    func returnError(err error) C.SEXP {
+        out, _ := rgo.ExportRSEXP[C.SEXP](rgo.ErrorCondition(err))
+        return out
+    }
+
+Once it is compiled to a shared library, the function can be called using R's .Call() interface. Passing the output
+through rgo_signal turns any error condition into a real R error:
 
 	input = c(0, 2.71, 3.14)
-	output = .Call("DoubleVector", input)
+	output = .Call("rgo_signal", .Call("DoubleVector", input))
 	print(output)
 
 The result would look like this:
@@ -0,0 +1,196 @@
+// Package embed runs an R interpreter inside a Go program, so that Go code can use R without being loaded by R first.
+// It is the opposite of the rgo package's usual setup, where R loads a Go shared library and calls into it.
+//
+// R is single threaded, and it keeps track of the stack of the thread it was started on. Because of that, every call
+// into R has to happen on the same OS thread. Start creates that thread, and Do runs a function on it:
+//
+//	if err := embed.Start(); err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer embed.Stop()
+//
+//	err := embed.Do(func() error {
+//	    // any rgo function can be used here
+//	})
+//
+// The R_HOME environment variable must point at the R installation, which `R RHOME` prints. R can only be started once
+// per process, so it can't be started again after Stop.
+package embed
+
+/*
+#define USE_RINTERNALS
+#define CSTACK_DEFNS
+#include <stdlib.h>
+#include <Rinternals.h>
+#include <Rembedded.h>
+#include <Rinterface.h>
+
+// startR does the same as Rf_initEmbeddedR, except that it turns off R's signal handlers, which would replace Go's,
+// and R's check of the C stack, which doesn't work on a thread that isn't the main thread.
+void startR(int argc, char **argv) {
+	R_SignalHandlers = 0;
+	Rf_initialize_R(argc, argv);
+	R_CStackLimit = (uintptr_t) -1;
+	R_Interactive = FALSE;
+	setup_Rmainloop();
+}
+
+#cgo CFLAGS: -I${SRCDIR}/../Rheader
+#cgo LDFLAGS: -L/Library/Frameworks/R.framework/Libraries
+#cgo LDFLAGS: -L/usr/lib
+#cgo LDFLAGS: -lR
+*/
+import "C"
+import (
+	"errors"
+	"os"
+	"runtime"
+	"sync"
+	"unsafe"
+
+	"github.com/EMurray16/rgo/v2"
+)
+
+// NotStarted is returned when R is used before Start has been called, or after Stop has been called.
+var NotStarted = errors.New("embedded R is not running")
+
+// AlreadyStarted is returned when Start is called more than once. R can only be started once per process, even if it
+// has been stopped.
+var AlreadyStarted = errors.New("embedded R has already been started")
+
+// NoRHome is returned by Start when the R_HOME environment variable is not set, since R can't find its own files
+// without it.
+var NoRHome = errors.New("R_HOME environment variable is not set")
+
+// defaultArgs are the command line arguments R is started with if none are given to Start.
+var defaultArgs = []string{"--silent", "--no-save", "--no-restore"}
+
+var (
+	// mu guards the state of the R thread, which is started, running, and then stopped
+	mu      sync.Mutex
+	started bool
+	running bool
+
+	// requests holds functions waiting to be run on the R thread
+	requests chan request
+
+	// stopped is closed once R has shut down
+	stopped chan struct{}
+)
+
+// request is a function to run on the R thread and a channel to send back its result.
+type request struct {
+	f    func() error
+	done chan error
+}
+
+// Start starts R on a new OS thread which is dedicated to it. The arguments are the same as the command line arguments
+// of R, like "--vanilla". If none are given, R is started with "--silent", "--no-save", and "--no-restore".
+//
+// If the R_HOME environment variable isn't set, a NoRHome error is returned. If R has already been started, an
+// AlreadyStarted error is returned.
+func Start(args ...string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if started {
+		return AlreadyStarted
+	}
+	if os.Getenv("R_HOME") == "" {
+		return NoRHome
+	}
+	if len(args) == 0 {
+		args = defaultArgs
+	}
+
+	requests = make(chan request)
+	stopped = make(chan struct{})
+	ready := make(chan struct{})
+	go serve(append([]string{"R"}, args...), ready)
+	<-ready
+
+	started, running = true, true
+	return nil
+}
+
+// serve starts R and then runs every request on the same locked OS thread until the requests channel is closed.
+func serve(args []string, ready chan struct{}) {
+	// the thread is never unlocked, so that Go retires it when this goroutine ends instead of reusing it
+	runtime.LockOSThread()
+
+	argv := make([]*C.char, len(args))
+	for i, arg := range args {
+		argv[i] = C.CString(arg)
+	}
+	C.startR(C.int(len(argv)), &argv[0])
+	// R copies its arguments, so they can be freed now
+	for _, arg := range argv {
+		C.free(unsafe.Pointer(arg))
+	}
+	close(ready)
+
+	for req := range requests {
+		// a panic that got out would end the R thread without shutting R down, and take the whole program with it
+		req.done <- rgo.CatchPanic(req.f)
+	}
+	C.Rf_endEmbeddedR(0)
+	close(stopped)
+}
+
+// Do runs a function on the R thread and returns its error. All use of R, including every function in the rgo package,
+// must be inside a function given to Do. RSEXPs made inside the function should be converted to Go data before it
+// returns, since R is free to garbage collect them afterwards.
+//
+// Do waits for any other functions to finish first, so it's safe to call from many goroutines. However, calling Do
+// from inside a function given to Do will deadlock. If R is not running, a NotStarted error is returned. If the function
+// panics, the panic is recovered on the R thread and returned as an error wrapping rgo.GoPanic, with the stack trace,
+// and R keeps running.
+func Do(f func() error) error {
+	mu.Lock()
+	if !running {
+		mu.Unlock()
+		return NotStarted
+	}
+	req := request{f: f, done: make(chan error, 1)}
+	requests <- req
+	mu.Unlock()
+
+	return <-req.done
+}
+
+// RunString runs R code in R's global environment, like running it in the R console. The code can contain any number
+// of expressions, which are run in order. If the code can't be parsed, an *rgo.ParseError is returned with the line and
+// column of the problem. If one of its expressions raises an error, an rgo.RError is returned and nothing after it is
+// run. R prints the error message as usual.
+func RunString(code string) error {
+	return Do(func() error {
+		_, err := rgo.Eval(code, nil)
+		return err
+	})
+}
+
+// RunFile runs an R source file in R's global environment, the same as RunString.
+func RunFile(path string) error {
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return RunString(string(code))
+}
+
+// Stop waits for every function given to Do to finish, then shuts down R. After R is stopped, it can't be started
+// again. If R is not running, a NotStarted error is returned.
+func Stop() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !running {
+		return NotStarted
+	}
+	running = false
+
+	// the R thread shuts down R once it runs out of requests
+	close(requests)
+	<-stopped
+	return nil
+}
@@ -0,0 +1,64 @@
+package embed
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/EMurray16/rgo/v2"
+)
+
+func TestEmbeddedR(t *testing.T) {
+	if os.Getenv("R_HOME") == "" {
+		if err := Start(); err != NoRHome {
+			t.Errorf("expected a missing R_HOME error but got %v", err)
+		}
+		t.Skip("R_HOME is not set, so R can't be started")
+	}
+
+	if err := Do(func() error { return nil }); err != NotStarted {
+		t.Errorf("expected a not started error but got %v", err)
+	}
+	if err := Start(); err != nil {
+		t.Fatalf("got unexpected error starting R: %v", err)
+	}
+	if err := Start(); err != AlreadyStarted {
+		t.Errorf("expected an already started error but got %v", err)
+	}
+
+	// a panic comes back as an error, and R keeps working afterwards
+	err := Do(func() error {
+		var s []int
+		_ = s[3]
+		return nil
+	})
+	if !errors.Is(err, rgo.GoPanic) {
+		t.Errorf("expected a Go panic error but got %v", err)
+	}
+
+	if err := RunString("x <- c(1, 2, 3)\ny <- sum(x)"); err != nil {
+		t.Errorf("got unexpected error: %v", err)
+	}
+	if err := RunString("x <- c(1, 2"); !errors.Is(err, rgo.RError) {
+		t.Errorf("expected an R error for incomplete code but got %v", err)
+	}
+	if err := RunString(`stop("on purpose")`); !errors.Is(err, rgo.RError) {
+		t.Errorf("expected an R error but got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "script.R")
+	if err := os.WriteFile(path, []byte("z <- rnorm(10)\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunFile(path); err != nil {
+		t.Errorf("got unexpected error: %v", err)
+	}
+
+	if err := Stop(); err != nil {
+		t.Errorf("got unexpected error stopping R: %v", err)
+	}
+	if err := RunString("1"); err != NotStarted {
+		t.Errorf("expected a not started error but got %v", err)
+	}
+}
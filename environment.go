@@ -0,0 +1,106 @@
+package rgo
+
+/*
+#define USE_RINTERNALS
+#include <Rinternals.h>
+// defineVar raises an R error when the environment or the variable is locked, like the variables in a package. Just
+// like setAttrib, it's run with R_ToplevelExec so the error doesn't jump over any Go code.
+typedef struct {
+	SEXP sym;
+	SEXP value;
+	SEXP env;
+} defineArgs;
+void doDefineVar(void *data) {
+	defineArgs *args = (defineArgs *) data;
+	defineVar(args->sym, args->value, args->env);
+}
+int safeDefineVar(SEXP sym, SEXP value, SEXP env) {
+	defineArgs args = {sym, value, env};
+	return R_ToplevelExec(doDefineVar, &args);
+}
+*/
+import "C"
+import "fmt"
+
+// GlobalEnv returns R's global environment, which is where variables made in the R console live.
+func GlobalEnv() RSEXP {
+	return RSEXP(C.R_GlobalEnv)
+}
+
+// BaseEnv returns the environment of R's base package, which holds functions like sum and paste.
+func BaseEnv() RSEXP {
+	return RSEXP(C.R_BaseEnv)
+}
+
+// Get returns the value of a variable in an R environment. Like get in R, it also looks in the environment's parents,
+// so for example the global environment can find functions in any attached package. If the variable isn't found, a
+// VariableNotFound error is returned, and if the input is not an environment, a TypeMismatch error is returned.
+//
+// Variables that haven't been used yet, like a function's lazily evaluated arguments, are evaluated first. If that
+// raises an error, an RError is returned. Like the output of Call, the value is not protected.
+func Get(env RSEXP, name string) (*RSEXP, error) {
+	if TYPEOF(env) != ENVSXP {
+		return nil, TypeMismatch
+	}
+
+	value := C.findVar(installSymbol(name), env)
+	if value == C.R_UnboundValue {
+		return nil, fmt.Errorf("%w: %s", VariableNotFound, name)
+	}
+
+	// a promise holds an expression that hasn't been evaluated yet, and evaluating it gives the value
+	if TYPEOF(RSEXP(value)) == RSEXPTYPE(C.PROMSXP) {
+		return evalSafely(value, C.SEXP(env))
+	}
+	out := RSEXP(value)
+	return &out, nil
+}
+
+// Exists reports whether a variable exists in an R environment or any of its parents, like exists in R. If the input
+// is not an environment, a TypeMismatch error is returned.
+func Exists(env RSEXP, name string) (bool, error) {
+	if TYPEOF(env) != ENVSXP {
+		return false, TypeMismatch
+	}
+	return C.findVar(installSymbol(name), env) != C.R_UnboundValue, nil
+}
+
+// Assign sets the value of a variable in an R environment, creating it if it doesn't exist, like assign in R. Unlike
+// Get, it only ever changes the environment itself and never its parents. A nil value assigns R's NULL, the same as
+// assign(name, NULL) in R. If the input is not an environment, a TypeMismatch error is returned. If R refuses to assign
+// the variable, because the environment or the variable is locked, an RError is returned.
+func Assign(env RSEXP, name string, value *RSEXP) error {
+	if TYPEOF(env) != ENVSXP {
+		return TypeMismatch
+	}
+
+	var ps ProtectStack
+	defer ps.Unprotect()
+
+	valSEXP := C.R_NilValue
+	if value != nil {
+		valSEXP = ps.protect(C.SEXP(*value))
+	}
+	if C.safeDefineVar(installSymbol(name), valSEXP, env) == 0 {
+		return fmt.Errorf("%w: could not assign variable %s", RError, name)
+	}
+	return nil
+}
+
+// Ls returns the names of the variables in an R environment, sorted, like ls in R. Names which start with a dot are
+// only included if all is true. If the input is not an environment, a TypeMismatch error is returned.
+func Ls(env RSEXP, all bool) ([]string, error) {
+	if TYPEOF(env) != ENVSXP {
+		return nil, TypeMismatch
+	}
+
+	var ps ProtectStack
+	defer ps.Unprotect()
+
+	allNames := C.Rboolean(C.FALSE)
+	if all {
+		allNames = C.TRUE
+	}
+	names := ps.protect(C.R_lsInternal3(env, allNames, C.TRUE))
+	return AsCharacter[string](RSEXP(names))
+}
@@ -0,0 +1,68 @@
+package rgo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEnvironment(t *testing.T) {
+	withR(t, func(t testing.TB, ps *ProtectStack) {
+		results, err := Eval("new.env(parent = globalenv())", nil)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		env := ps.Protect(results[0])
+
+		if err := Assign(*env, "b", ps.Protect(NumericToRSEXP([]float64{2}))); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if err := Assign(*env, "a", ps.Protect(CharacterToRSEXP([]string{"one"}))); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+
+		names, err := Ls(*env, false)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(names, []string{"a", "b"}) {
+			t.Errorf("expected variables a and b but got %v", names)
+		}
+
+		b, err := Get(*env, "b")
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if out, _ := AsNumeric[float64](*b); out[0] != 2 {
+			t.Errorf("expected b to be 2 but got %v", out)
+		}
+
+		// a nil value is R's NULL
+		if err := Assign(*env, "empty", nil); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if empty, err := Get(*env, "empty"); err != nil || *empty != *rNull() {
+			t.Errorf("expected empty to be NULL but got %v", err)
+		}
+
+		// functions in base are found through the environment's parents
+		if ok, _ := Exists(*env, "sum"); !ok {
+			t.Error("expected to find sum through the parent environments")
+		}
+		if _, err := Get(*env, "nope"); !errors.Is(err, VariableNotFound) {
+			t.Errorf("expected a variable not found error but got %v", err)
+		}
+
+		// nothing can be added to a locked environment
+		results, err = Eval("locked <- new.env()\nlockEnvironment(locked)\nlocked", nil)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if err := Assign(*results[2], "b", b); !errors.Is(err, RError) {
+			t.Errorf("expected an R error but got %v", err)
+		}
+		if _, err := Ls(*ps.Protect(NumericToRSEXP([]float64{1})), true); err != TypeMismatch {
+			t.Errorf("expected a type mismatch but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package rgo
+
+/*
+#define USE_RINTERNALS
+#include <Rinternals.h>
+#include <R_ext/Parse.h>
+// ParseStatus is an enum, so we pass it back to Go as an int
+SEXP parseVector(SEXP text, int *status) {
+	ParseStatus s;
+	SEXP out = R_ParseVector(text, -1, &s, R_NilValue);
+	*status = s;
+	return out;
+}
+SEXP parseFunction() {
+	return findFun(install("parse"), R_BaseEnv);
+}
+*/
+import "C"
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ParseError is returned by Eval when the R code can't be parsed. It includes where in the code the problem is, using
+// 1-based lines and columns like R's own parse errors. A ParseError wraps RError, so errors.Is(err, RError) is true.
+type ParseError struct {
+	Line, Column int
+	Msg          string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("R parse error at line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+func (e *ParseError) Unwrap() error {
+	return RError
+}
+
+// Eval parses a string of R code and evaluates each of its expressions in order, in the given environment, the same as
+// running the code in the R console. The output has the result of each expression. A nil environment evaluates the code
+// in the global environment, and otherwise it must be an R environment, or a TypeMismatch error is returned.
+//
+// If the code can't be parsed, a *ParseError is returned with the line and column of the problem, and nothing is
+// evaluated. If an expression raises an error, an RError is returned with the results of the expressions before it.
+//
+// Like the output of Call, the results are not protected once Eval returns, so they need to be protected if they're kept
+// while anything else is allocated in R.
+func Eval(code string, env RSEXP) ([]*RSEXP, error) {
+	if env == nil {
+		env = RSEXP(C.R_GlobalEnv)
+	} else if TYPEOF(env) != ENVSXP {
+		return nil, TypeMismatch
+	}
+
+	var ps ProtectStack
+	defer ps.Unprotect()
+
+	text := ps.Protect(CharacterToRSEXP([]string{code}))
+	var status C.int
+	exprs := ps.protect(C.parseVector(*text, &status))
+	if status != C.PARSE_OK {
+		return nil, parseError(*text)
+	}
+
+	out := make([]*RSEXP, 0, LENGTH(RSEXP(exprs)))
+	for i := 0; i < LENGTH(RSEXP(exprs)); i++ {
+		result, err := evalSafely(C.VECTOR_ELT(exprs, C.long(i)), C.SEXP(env))
+		if err != nil {
+			return out, err
+		}
+		// earlier results have to survive the evaluation of later expressions
+		out = append(out, ps.Protect(result))
+	}
+	return out, nil
+}
+
+// parseErrorLocation finds the line, column, and message in one of R's parse errors, which look like
+// "<text>:2:5: unexpected symbol".
+var parseErrorLocation = regexp.MustCompile(`<text>:(\d+):(\d+): ([^\n]*)`)
+
+// parseError finds out why R code couldn't be parsed. R_ParseVector only says that there was an error, so the code is
+// parsed again with R's parse function, and the location is read from its error message.
+func parseError(text RSEXP) error {
+	var ps ProtectStack
+	defer ps.Unprotect()
+
+	call := ps.protect(C.lang2(C.parseFunction(), text))
+	C.SET_TAG(C.CDR(call), installSymbol("text"))
+
+	var errorOccurred C.int
+	C.R_tryEvalSilent(call, C.R_BaseEnv, &errorOccurred)
+	if errorOccurred == 0 {
+		// this shouldn't happen, but if parse is happy there's nothing more we can say
+		return &ParseError{Msg: "could not parse R code"}
+	}
+	return parseErrorFromMessage(lastErrorMessage())
+}
+
+// parseErrorFromMessage creates a ParseError from the text of an error raised by R's parse function.
+func parseErrorFromMessage(msg string) *ParseError {
+	match := parseErrorLocation.FindStringSubmatch(msg)
+	if match == nil {
+		return &ParseError{Msg: strings.TrimSpace(msg)}
+	}
+
+	// the pattern only matches digits, so these can't fail unless the numbers are absurdly large
+	line, _ := strconv.Atoi(match[1])
+	col, _ := strconv.Atoi(match[2])
+	return &ParseError{Line: line, Column: col, Msg: match[3]}
+}
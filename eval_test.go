@@ -0,0 +1,58 @@
+package rgo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseErrorFromMessage(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want ParseError
+	}{
+		{"Error in parse(text = \"x <- c(1, 2\") : <text>:2:0: unexpected end of input\n1: x <- c(1, 2\n   ^\n",
+			ParseError{Line: 2, Column: 0, Msg: "unexpected end of input"}},
+		{"<text>:1:3: unexpected symbol\n1: a b\n      ^", ParseError{Line: 1, Column: 3, Msg: "unexpected symbol"}},
+		{"something else entirely\n", ParseError{Msg: "something else entirely"}},
+	}
+
+	for _, test := range tests {
+		got := parseErrorFromMessage(test.msg)
+		if *got != test.want {
+			t.Errorf("expected %v but got %v", test.want, *got)
+		}
+	}
+
+	if !errors.Is(&ParseError{}, RError) {
+		t.Error("a parse error should also be an R error")
+	}
+}
+
+func TestEval(t *testing.T) {
+	withR(t, func(t testing.TB, ps *ProtectStack) {
+		results, err := Eval("x <- c(1, 5, 9)\nquantile(x, 0.5)", nil)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if len(results) != 2 {
+			t.Fatalf("expected 2 results but got %d", len(results))
+		}
+		median, _ := AsNumeric[float64](*results[1])
+		if median[0] != 5 {
+			t.Errorf("expected the median to be 5 but got %v", median)
+		}
+
+		var parseErr *ParseError
+		if _, err := Eval("x <- 1\ny <- )", nil); !errors.As(err, &parseErr) || parseErr.Line != 2 {
+			t.Errorf("expected a parse error on line 2 but got %v", err)
+		}
+		if _, err := Eval(`stop("on purpose")`, nil); !errors.Is(err, RError) {
+			t.Errorf("expected an R error but got %v", err)
+		}
+
+		notAnEnv := ps.Protect(NumericToRSEXP([]float64{1}))
+		if _, err := Eval("1", *notAnEnv); err != TypeMismatch {
+			t.Errorf("expected a type mismatch but got %v", err)
+		}
+	})
+}
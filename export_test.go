@@ -0,0 +1,13 @@
+package rgo
+
+// These are only exported to the tests in the rgo_test package, which can start R with the embed package. The embed
+// package imports rgo, so the tests that start it can't be part of package rgo itself.
+var (
+	WithR        = withR
+	SetGCTorture = setGCTorture
+)
+
+// SetRThread sets the function the tests use to run on R's thread.
+func SetRThread(do func(func() error) error) {
+	rThread = do
+}
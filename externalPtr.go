@@ -0,0 +1,84 @@
+package rgo
+
+/*
+#define USE_RINTERNALS
+#include <stdint.h>
+#include <Rinternals.h>
+extern void rgoReleaseHandle(uintptr_t h);
+// The address of an Rgo external pointer isn't really a pointer, it's a cgo.Handle, which R never looks at. A released
+// pointer has an address of 0, which is never a valid handle.
+static SEXP handleTag() {
+	return install("rgo_handle");
+}
+static void finalizeHandle(SEXP ptr) {
+	uintptr_t h = (uintptr_t) R_ExternalPtrAddr(ptr);
+	if (h != 0) {
+		R_ClearExternalPtr(ptr);
+		rgoReleaseHandle(h);
+	}
+}
+SEXP makeHandlePtr(uintptr_t h) {
+	SEXP ptr = PROTECT(R_MakeExternalPtr((void *) h, handleTag(), R_NilValue));
+	R_RegisterCFinalizerEx(ptr, finalizeHandle, TRUE);
+	UNPROTECT(1);
+	return ptr;
+}
+int isHandlePtr(SEXP ptr) {
+	return TYPEOF(ptr) == EXTPTRSXP && R_ExternalPtrTag(ptr) == handleTag();
+}
+uintptr_t handleOf(SEXP ptr) {
+	return (uintptr_t) R_ExternalPtrAddr(ptr);
+}
+void releaseHandlePtr(SEXP ptr) {
+	finalizeHandle(ptr);
+}
+*/
+import "C"
+import (
+	"fmt"
+	"runtime/cgo"
+)
+
+// ExternalPtrToRSEXP wraps a Go value in an R external pointer, so that it can be kept in R between calls to Go, like
+// a loaded model or an open database connection. R can't see inside the pointer, but it can pass it back to Go, where
+// AsExternalPtr returns the original value.
+//
+// The Go value is kept alive for as long as R keeps the pointer. Once R garbage collects the pointer, or R exits, the
+// value is released so that Go can garbage collect it too. It can also be released sooner with ReleaseExternalPtr.
+func ExternalPtrToRSEXP(value any) *RSEXP {
+	h := cgo.NewHandle(value)
+	out := RSEXP(C.makeHandlePtr(C.uintptr_t(h)))
+	return &out
+}
+
+// AsExternalPtr returns the Go value inside an external pointer made by ExternalPtrToRSEXP. If the RSEXP isn't one of
+// Rgo's external pointers, or the value inside it isn't of the given type, a TypeMismatch error is returned. If the
+// value has already been released, a ReleasedPointer error is returned.
+func AsExternalPtr[t any](r RSEXP) (out t, err error) {
+	if C.isHandlePtr(r) == 0 {
+		return out, TypeMismatch
+	}
+	h := C.handleOf(r)
+	if h == 0 {
+		return out, ReleasedPointer
+	}
+
+	out, ok := cgo.Handle(h).Value().(t)
+	if !ok {
+		return out, fmt.Errorf("%w: external pointer holds a %T", TypeMismatch, cgo.Handle(h).Value())
+	}
+	return out, nil
+}
+
+// ReleaseExternalPtr releases the Go value inside an external pointer made by ExternalPtrToRSEXP, without waiting for
+// R to garbage collect it. Any use of the pointer afterwards returns a ReleasedPointer error. Releasing a pointer more
+// than once does nothing. If the RSEXP isn't one of Rgo's external pointers, a TypeMismatch error is returned.
+//
+// Releasing the value doesn't close or clean it up, so something like a database connection should be closed first.
+func ReleaseExternalPtr(r RSEXP) error {
+	if C.isHandlePtr(r) == 0 {
+		return TypeMismatch
+	}
+	C.releaseHandlePtr(r)
+	return nil
+}
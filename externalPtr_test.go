@@ -0,0 +1,47 @@
+package rgo
+
+import (
+	"errors"
+	"testing"
+)
+
+type testModel struct {
+	coefficients []float64
+}
+
+func TestExternalPtr(t *testing.T) {
+	withR(t, func(t testing.TB, ps *ProtectStack) {
+		model := &testModel{coefficients: []float64{1.5, -2}}
+		ptr := ps.Protect(ExternalPtrToRSEXP(model))
+		if TYPEOF(*ptr) != EXTPTRSXP {
+			t.Fatalf("expected an external pointer but got type %v", TYPEOF(*ptr))
+		}
+
+		out, err := AsExternalPtr[*testModel](*ptr)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if out != model {
+			t.Error("got a different model back than the one that went in")
+		}
+
+		if _, err := AsExternalPtr[string](*ptr); !errors.Is(err, TypeMismatch) {
+			t.Errorf("expected a type mismatch but got %v", err)
+		}
+		notAPointer := ps.Protect(NumericToRSEXP([]float64{1}))
+		if _, err := AsExternalPtr[*testModel](*notAPointer); err != TypeMismatch {
+			t.Errorf("expected a type mismatch but got %v", err)
+		}
+
+		if err := ReleaseExternalPtr(*ptr); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if _, err := AsExternalPtr[*testModel](*ptr); err != ReleasedPointer {
+			t.Errorf("expected a released pointer error but got %v", err)
+		}
+		// releasing twice is harmless
+		if err := ReleaseExternalPtr(*ptr); err != nil {
+			t.Errorf("got unexpected error: %v", err)
+		}
+	})
+}
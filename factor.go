@@ -0,0 +1,166 @@
+package rgo
+
+/*
+#define USE_RINTERNALS
+#include <Rinternals.h>
+*/
+import "C"
+import (
+	"fmt"
+	"sort"
+)
+
+// Factor is the Go representation of an R factor. A factor stores each value as a code which indexes into a set of
+// levels, which are strings. Unlike R, the codes in a Factor use 0-based indexing, so that a code of 0 refers to
+// Levels[0]. NA values have a code of -1.
+//
+// For example, the R factor factor(c("b", "a", NA, "b")) is the following Factor:
+//
+//	Factor{Codes: []int{1, 0, -1, 1}, Levels: []string{"a", "b"}}
+//
+// Ordered factors, which R creates with ordered() or factor(..., ordered = TRUE), have Ordered set to true. The order
+// of the levels is then meaningful, with Levels[0] being the smallest.
+type Factor struct {
+	Codes   []int
+	Levels  []string
+	Ordered bool
+}
+
+// NewFactor creates a Factor from a slice of strings in the same way as R's factor function. The levels are the unique
+// values in the input, sorted. Use FactorToRSEXP to send the result to R.
+func NewFactor(values []string) Factor {
+	// find the unique values and sort them
+	seen := make(map[string]bool)
+	var levels []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			levels = append(levels, v)
+		}
+	}
+	sort.Strings(levels)
+
+	// now each value's code is its index in the levels
+	index := make(map[string]int, len(levels))
+	for i, l := range levels {
+		index[l] = i
+	}
+	codes := make([]int, len(values))
+	for i, v := range values {
+		codes[i] = index[v]
+	}
+
+	return Factor{Codes: codes, Levels: levels}
+}
+
+// Values decodes a Factor into a slice of strings, where each element is the level of the matching code. NA values
+// are returned as the string "NA", which is consistent with AsCharacter. Codes which aren't valid indexes of the
+// levels are also treated as NA.
+func (f *Factor) Values() []string {
+	out := make([]string, len(f.Codes))
+	for i, code := range f.Codes {
+		if code < 0 || code >= len(f.Levels) {
+			out[i] = "NA"
+			continue
+		}
+		out[i] = f.Levels[code]
+	}
+	return out
+}
+
+// isValid checks that every code in a Factor is either NA or a valid index of its levels. It returns an InvalidIndex
+// error if a code is less than -1 and an IndexOutOfBounds error if a code is too large for the levels.
+func (f *Factor) isValid() error {
+	for i, code := range f.Codes {
+		if code < -1 {
+			return fmt.Errorf("%w: code %d at index %d", InvalidIndex, code, i)
+		}
+		if code >= len(f.Levels) {
+			return fmt.Errorf("%w: code %d at index %d with %d levels", IndexOutOfBounds, code, i, len(f.Levels))
+		}
+	}
+	return nil
+}
+
+// AsFactor extracts the codes and levels of an R factor and returns them as a Factor. If the input RSEXP is not a
+// factor, the TypeMismatch error is returned. To get the values of the factor as strings, use the Values method or
+// AsFactorValues.
+func AsFactor(r RSEXP) (out Factor, err error) {
+	if C.isFactor(r) == 0 {
+		return out, TypeMismatch
+	}
+
+	codes, na, err := AsNumericNA[int](r)
+	if err != nil {
+		return out, err
+	}
+	// R's codes start at 1, and ours start at 0
+	for i := range codes {
+		if na[i] {
+			codes[i] = -1
+			continue
+		}
+		codes[i]--
+	}
+	out.Codes = codes
+
+	out.Levels, err = AsCharacter[string](RSEXP(C.getAttrib(r, C.R_LevelsSymbol)))
+	if err != nil {
+		return out, err
+	}
+	out.Ordered = C.isOrdered(r) != 0
+
+	return out, nil
+}
+
+// AsFactorValues is a convenience function which extracts an R factor and decodes it into a slice of strings, the same
+// as calling Values on the output of AsFactor. If the input RSEXP is not a factor, the TypeMismatch error is returned.
+func AsFactorValues(r RSEXP) ([]string, error) {
+	f, err := AsFactor(r)
+	if err != nil {
+		return nil, err
+	}
+	return f.Values(), nil
+}
+
+// FactorToRSEXP converts a Factor into an R factor, represented by the returned RSEXP data. If the Factor is ordered,
+// the R factor will be too. Factors can be used as columns in MakeDataFrame.
+//
+// If any code is less than -1 (which means NA), an InvalidIndex error is returned, and if any code is too large for the
+// levels, an IndexOutOfBounds error is returned.
+func FactorToRSEXP(in Factor) (*RSEXP, error) {
+	if err := in.isValid(); err != nil {
+		return nil, err
+	}
+
+	// R's codes start at 1, and ours start at 0
+	codes := make([]int, len(in.Codes))
+	na := make([]bool, len(in.Codes))
+	for i, code := range in.Codes {
+		if code == -1 {
+			na[i] = true
+			continue
+		}
+		codes[i] = code + 1
+	}
+
+	var ps ProtectStack
+	defer ps.Unprotect()
+
+	out, err := IntegerToRSEXPNA(codes, na)
+	if err != nil {
+		return nil, err
+	}
+	ps.Protect(out)
+
+	class := []string{"factor"}
+	if in.Ordered {
+		class = []string{"ordered", "factor"}
+	}
+	levelSEXP := ps.Protect(CharacterToRSEXP(in.Levels))
+	classSEXP := ps.Protect(CharacterToRSEXP(class))
+	C.setAttrib(*out, C.R_LevelsSymbol, *levelSEXP)
+	C.setAttrib(*out, C.R_ClassSymbol, *classSEXP)
+
+	return out, nil
+}
@@ -0,0 +1,92 @@
+package rgo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewFactor(t *testing.T) {
+	f := NewFactor([]string{"b", "a", "c", "b"})
+	expectedCodes := []int{1, 0, 2, 1}
+	expectedLevels := []string{"a", "b", "c"}
+
+	if len(f.Codes) != len(expectedCodes) || len(f.Levels) != len(expectedLevels) {
+		t.Fatalf("expected codes %v and levels %v but got %v", expectedCodes, expectedLevels, f)
+	}
+	for i, code := range f.Codes {
+		if code != expectedCodes[i] {
+			t.Errorf("expected codes %v but got %v", expectedCodes, f.Codes)
+		}
+	}
+	for i, level := range f.Levels {
+		if level != expectedLevels[i] {
+			t.Errorf("expected levels %v but got %v", expectedLevels, f.Levels)
+		}
+	}
+	if f.Ordered {
+		t.Error("expected a new factor to be unordered")
+	}
+}
+
+func TestFactor_Values(t *testing.T) {
+	f := Factor{Codes: []int{1, 0, -1, 1, 5}, Levels: []string{"a", "b"}}
+	expected := []string{"b", "a", "NA", "b", "NA"}
+
+	values := f.Values()
+	if len(values) != len(expected) {
+		t.Fatalf("expected %v but got %v", expected, values)
+	}
+	for i, v := range values {
+		if v != expected[i] {
+			t.Errorf("expected %v but got %v", expected, values)
+		}
+	}
+}
+
+func TestFactor_isValid(t *testing.T) {
+	good := Factor{Codes: []int{1, 0, -1}, Levels: []string{"a", "b"}}
+	if err := good.isValid(); err != nil {
+		t.Errorf("expected a valid factor but got %v", err)
+	}
+
+	tooSmall := Factor{Codes: []int{1, -2}, Levels: []string{"a", "b"}}
+	if err := tooSmall.isValid(); !errors.Is(err, InvalidIndex) {
+		t.Errorf("expected an invalid index error but got %v", err)
+	}
+
+	tooBig := Factor{Codes: []int{2}, Levels: []string{"a", "b"}}
+	if err := tooBig.isValid(); !errors.Is(err, IndexOutOfBounds) {
+		t.Errorf("expected an index out of bounds error but got %v", err)
+	}
+}
+
+func TestFactorToRSEXP(t *testing.T) {
+	withR(t, func(t testing.TB, ps *ProtectStack) {
+		in := Factor{Codes: []int{1, 0, -1}, Levels: []string{"low", "high"}, Ordered: true}
+
+		r, err := FactorToRSEXP(in)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ps.Protect(r)
+
+		out, err := AsFactor(*r)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if !out.Ordered || len(out.Codes) != 3 || out.Codes[0] != 1 || out.Codes[2] != -1 || out.Levels[1] != "high" {
+			t.Errorf("expected %v but got %v", in, out)
+		}
+
+		// a factor is a valid data frame column
+		if _, err := MakeDataFrame(nil, []string{"f"}, r); err != nil {
+			t.Errorf("expected a factor to be a valid column but got %v", err)
+		}
+
+		// a plain integer vector isn't a factor
+		ints, _ := IntegerToRSEXP([]int{1, 2})
+		if _, err := AsFactor(*ints); err != TypeMismatch {
+			t.Errorf("expected a type mismatch but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package rgo
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// Guard runs the body of an exported Go function and turns anything that goes wrong into an R error condition, rather
+// than crashing R. If the function returns an error, the output is an ErrorCondition made from it. If it panics, the
+// panic is recovered and the output is an ErrorCondition with the "rgo_panic" class, whose message includes the panic
+// value and the Go stack trace. Otherwise, the output of the function is returned as it is, and a nil output is
+// returned as NULL.
+//
+// A panic that isn't recovered kills the whole R session, along with any work the user hasn't saved, so every exported
+// function should use Guard:
+//
+//	//export MyGoFunction
+//	func MyGoFunction(input C.SEXP) C.SEXP {
+//	    out := rgo.Guard(func() (*rgo.RSEXP, error) {
+//	        r, err := rgo.NewRSEXP(&input)
+//	        if err != nil {
+//	            return nil, err
+//	        }
+//	        ...
+//	    })
+//	    mySEXP, _ := rgo.ExportRSEXP[C.SEXP](out)
+//	    return mySEXP
+//	}
+//
+// Like other conditions, the output should be passed through rgo_signal in R to raise the error. Guard can only
+// recover panics in the goroutine it was called from, so panics in other goroutines will still crash R.
+func Guard(f func() (*RSEXP, error)) *RSEXP {
+	var out *RSEXP
+	err := CatchPanic(func() (err error) {
+		out, err = f()
+		return err
+	})
+	if err != nil {
+		return ErrorCondition(err)
+	}
+	if out == nil {
+		return rNull()
+	}
+	return out
+}
+
+// CatchPanic calls f and returns its error. If f panics, the panic is recovered and returned as an error that wraps
+// GoPanic, with the panic value and the Go stack trace. Guard uses it for exported functions, and the embed package
+// uses it so that a panic doesn't take down the thread R runs on.
+func CatchPanic(f func() error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%w: %v\n\n%s", GoPanic, p, debug.Stack())
+		}
+	}()
+	return f()
+}
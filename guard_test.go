@@ -0,0 +1,40 @@
+package rgo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCatchPanic(t *testing.T) {
+	// errors are passed through unchanged
+	err := CatchPanic(func() error { return SizeMismatch })
+	if err != SizeMismatch {
+		t.Errorf("expected a size mismatch but got %v", err)
+	}
+
+	// panics become errors with the stack trace
+	err = CatchPanic(func() error {
+		var m map[string]int
+		m["boom"] = 1
+		return nil
+	})
+	if !errors.Is(err, GoPanic) {
+		t.Fatalf("expected a Go panic error but got %v", err)
+	}
+	if !strings.Contains(err.Error(), "assignment to entry in nil map") {
+		t.Errorf("error does not include the panic value: %v", err)
+	}
+	if !strings.Contains(err.Error(), "TestCatchPanic") {
+		t.Errorf("error does not include the stack trace: %v", err)
+	}
+}
+
+func TestGuard(t *testing.T) {
+	withR(t, func(t testing.TB, ps *ProtectStack) {
+		out := ps.Protect(Guard(func() (*RSEXP, error) { panic("something went wrong") }))
+		if class, _ := GetClass(*out); len(class) == 0 || class[0] != "rgo_panic" {
+			t.Errorf("expected an rgo_panic condition but got class %v", class)
+		}
+	})
+}
@@ -0,0 +1,13 @@
+package rgo
+
+// A file with an export can only declare C functions in its preamble, not define them, so the export that releases
+// handles lives on its own here and the C code that calls it is in externalPtr.go.
+
+// #include <stdint.h>
+import "C"
+import "runtime/cgo"
+
+//export rgoReleaseHandle
+func rgoReleaseHandle(h C.uintptr_t) {
+	cgo.Handle(h).Delete()
+}
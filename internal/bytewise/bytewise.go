@@ -0,0 +1,52 @@
+// Package bytewise holds the original implementation of rgo's AsCharacter, which reads each CHARSXP one byte at a time.
+// It is only used as a baseline for benchmarking against AsCharacter. Go doesn't allow cgo in test files, so it can't
+// live in the rgo package's tests, and it's internal so it isn't part of the public API.
+package bytewise
+
+/*
+#define USE_RINTERNALS
+#include <Rinternals.h>
+
+char charExtract(SEXP s, int index) {
+	char output;
+	const char *cp = CHAR(s);
+	output = *(cp+index);
+	return output;
+}
+
+#cgo CFLAGS: -I${SRCDIR}/../../Rheader
+#cgo LDFLAGS: -L/Library/Frameworks/R.framework/Libraries
+#cgo LDFLAGS: -L/usr/lib
+#cgo LDFLAGS: -lR
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+)
+
+// TypeMismatch is returned when the input isn't a character vector.
+var TypeMismatch = errors.New("input SEXP is not a character vector")
+
+// AsCharacter reads an R character vector into a slice of strings. The input is an rgo.RSEXP, passed as an
+// unsafe.Pointer because the two packages each have their own C.SEXP type.
+func AsCharacter(p unsafe.Pointer) (out []string, err error) {
+	r := C.SEXP(p)
+	if C.TYPEOF(r) != C.STRSXP {
+		return nil, TypeMismatch
+	}
+
+	Slen := int(C.LENGTH(r))
+	out = make([]string, Slen)
+	for i := 0; i < Slen; i++ {
+		charsxp := C.STRING_ELT(r, C.long(i))
+		nChar := int(C.LENGTH(charsxp))
+		goBytes := make([]byte, 0, nChar)
+		for charInd := 0; charInd < nChar; charInd++ {
+			indChar := C.charExtract(charsxp, C.int(charInd))
+			goBytes = append(goBytes, C.GoBytes(unsafe.Pointer(&indChar), 1)...)
+		}
+		out[i] = string(goBytes)
+	}
+	return out, nil
+}
@@ -0,0 +1,28 @@
+package rgo_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/EMurray16/rgo/v2"
+	"github.com/EMurray16/rgo/v2/embed"
+)
+
+// TestMain starts R with the embed package when R_HOME is set, so that the tests which need R can run. Without it, they
+// are skipped.
+func TestMain(m *testing.M) {
+	if os.Getenv("R_HOME") == "" {
+		os.Exit(m.Run())
+	}
+
+	if err := embed.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "could not start R: %v\n", err)
+		os.Exit(1)
+	}
+	rgo.SetRThread(embed.Do)
+
+	code := m.Run()
+	embed.Stop()
+	os.Exit(code)
+}
@@ -0,0 +1,378 @@
+package rgo
+
+/*
+#define USE_RINTERNALS
+#include <Rinternals.h>
+int isPOSIXct(SEXP s) {
+	return inherits(s, "POSIXct");
+}
+int isDate(SEXP s) {
+	return inherits(s, "Date");
+}
+*/
+import "C"
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// timeType is the reflected type of time.Time, which is the only struct type that can be a data frame column.
+var timeType = reflect.TypeOf(time.Time{})
+
+// structColumn describes a field of a struct which maps onto a column of a data frame.
+type structColumn struct {
+	// name is the name of the data frame column
+	name string
+	// index is the index of the field in the struct
+	index int
+	// typ is the type of the field
+	typ reflect.Type
+}
+
+// MarshalDataFrame creates an R data frame from a slice of structs, where each field of the struct is a column and each
+// element of the slice is a row. The input can be a slice of structs, a slice of pointers to structs, or a pointer to
+// either one.
+//
+// The name of each column is the name of the field, unless the field has an rgo struct tag, in which case the tag is
+// used instead. Fields with a tag of "-" and unexported fields are skipped:
+//
+//	type Record struct {
+//		ID    int       `rgo:"id"`
+//		Value float64   `rgo:"value"`
+//		Label string    // the column is named Label
+//		When  time.Time `rgo:"when"`
+//		notes string    // unexported fields are skipped
+//		Temp  []int     `rgo:"-"`
+//	}
+//
+// Fields can be any float, int, string, or bool type, which become double, integer, character, and logical columns,
+// or a time.Time, which becomes a POSIXct column. If any other type is used, an UnsupportedType error is returned. If
+// an int field doesn't fit in an R integer, an IntegerOverflow error is returned. If the input is not a slice of
+// structs, or it's a slice of pointers and one of them is nil, a TypeMismatch error is returned.
+func MarshalDataFrame(in any) (*RSEXP, error) {
+	rows := reflect.ValueOf(in)
+	for rows.Kind() == reflect.Pointer {
+		rows = rows.Elem()
+	}
+	if rows.Kind() != reflect.Slice {
+		return nil, TypeMismatch
+	}
+
+	columns, err := structColumns(rows.Type().Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	// a nil row has no fields to read, so it can't be turned into a row of the data frame
+	if rows.Type().Elem().Kind() == reflect.Pointer {
+		for i := 0; i < rows.Len(); i++ {
+			if rows.Index(i).IsNil() {
+				return nil, fmt.Errorf("%w: row %d is nil", TypeMismatch, i)
+			}
+		}
+	}
+
+	var ps ProtectStack
+	defer ps.Unprotect()
+
+	colNames := make([]string, len(columns))
+	colSEXPs := make([]*RSEXP, len(columns))
+	for i, col := range columns {
+		colNames[i] = col.name
+		colSEXPs[i], err = marshalColumn(rows, col)
+		if err != nil {
+			return nil, fmt.Errorf("problem with column %s: %w", col.name, err)
+		}
+		ps.Protect(colSEXPs[i])
+	}
+
+	return MakeDataFrame(nil, colNames, colSEXPs...)
+}
+
+// UnmarshalDataFrame reads an R data frame into a slice of structs, which is the reverse of MarshalDataFrame. The
+// output must be a pointer to a slice of structs (or a slice of pointers to structs), which is replaced with one
+// element for each row in the data frame. Columns are matched to fields the same way as MarshalDataFrame, and columns
+// without a matching field are ignored.
+//
+// Numeric columns can be read into any float or int field, character columns into string fields, logical columns into
+// bool fields, and POSIXct or Date columns into time.Time fields. Go has no notion of NA, so NA values are left as the
+// zero value of the field. If the output is not a pointer to a slice of structs, or a column can't be read into its
+// field, a TypeMismatch error is returned. If a field has no matching column, an IndexOutOfBounds error is returned.
+//
+// Int fields only accept whole numbers, so a column with a fraction returns a TypeMismatch error rather than being
+// truncated, and a value that doesn't fit in the field (like 300 in an int8) returns an IntegerOverflow error.
+func UnmarshalDataFrame(r RSEXP, out any) error {
+	outVal := reflect.ValueOf(out)
+	if outVal.Kind() != reflect.Pointer || outVal.Elem().Kind() != reflect.Slice {
+		return TypeMismatch
+	}
+	rows := outVal.Elem()
+
+	columns, err := structColumns(rows.Type().Elem())
+	if err != nil {
+		return err
+	}
+
+	df, err := AsDataFrame(r)
+	if err != nil {
+		return err
+	}
+
+	// build the new slice, including the structs themselves if they are pointers
+	newRows := reflect.MakeSlice(rows.Type(), df.Nrow(), df.Nrow())
+	if rows.Type().Elem().Kind() == reflect.Pointer {
+		for i := 0; i < newRows.Len(); i++ {
+			newRows.Index(i).Set(reflect.New(rows.Type().Elem().Elem()))
+		}
+	}
+
+	for _, col := range columns {
+		colSEXP, err := df.Column(col.name)
+		if err != nil {
+			return err
+		}
+		if err := unmarshalColumn(colSEXP, newRows, col); err != nil {
+			return fmt.Errorf("problem with column %s: %w", col.name, err)
+		}
+	}
+
+	rows.Set(newRows)
+	return nil
+}
+
+// structColumns finds the fields of a struct type which map onto data frame columns. The type may also be a pointer to
+// a struct. If the type isn't a struct, a TypeMismatch error is returned, and if any of its fields can't be a column,
+// an UnsupportedType error is returned.
+func structColumns(typ reflect.Type) ([]structColumn, error) {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, TypeMismatch
+	}
+
+	var columns []structColumn
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, ok := fieldName(field)
+		if !ok {
+			continue
+		}
+
+		if !isColumnType(field.Type) {
+			return nil, fmt.Errorf("%w: field %s is a %v", UnsupportedType, field.Name, field.Type)
+		}
+		columns = append(columns, structColumn{name: name, index: i, typ: field.Type})
+	}
+
+	// MakeDataFrame needs at least one column to know how many rows there are
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("%w: %v has no fields that can be columns", UnsupportedType, typ)
+	}
+
+	return columns, nil
+}
+
+// fieldName returns the name a struct field should have in R, which is its rgo struct tag if it has one, or the name of
+// the field otherwise. Unexported fields and fields with a tag of "-" should be skipped, which is reported by ok being
+// false.
+func fieldName(field reflect.StructField) (name string, ok bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+
+	tag := field.Tag.Get("rgo")
+	switch tag {
+	case "-":
+		return "", false
+	case "":
+		return field.Name, true
+	}
+	return tag, true
+}
+
+// isColumnType checks whether a field of the given type can be a data frame column.
+func isColumnType(typ reflect.Type) bool {
+	if typ == timeType {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.String, reflect.Bool:
+		return true
+	}
+	return false
+}
+
+// fieldOf returns the field of the struct (or pointer to a struct) in a row that matches the column.
+func fieldOf(row reflect.Value, col structColumn) reflect.Value {
+	if row.Kind() == reflect.Pointer {
+		row = row.Elem()
+	}
+	return row.Field(col.index)
+}
+
+// marshalColumn creates the RSEXP for one column from the matching field of every row.
+func marshalColumn(rows reflect.Value, col structColumn) (*RSEXP, error) {
+	vals := reflect.MakeSlice(reflect.SliceOf(col.typ), rows.Len(), rows.Len())
+	for i := 0; i < rows.Len(); i++ {
+		vals.Index(i).Set(fieldOf(rows.Index(i), col))
+	}
+	return marshalVector(vals)
+}
+
+// unmarshalColumn reads one column of a data frame into the matching field of every row.
+func unmarshalColumn(r RSEXP, rows reflect.Value, col structColumn) error {
+	vals, err := unmarshalVector(r, col.typ)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < rows.Len(); i++ {
+		fieldOf(rows.Index(i), col).Set(vals.Index(i))
+	}
+	return nil
+}
+
+// marshalVector creates an R vector from a slice (or array) of floats, ints, strings, bools, or time.Times. Every
+// element must be of a type for which isColumnType is true, and the type of the R vector matches the type of the
+// elements.
+func marshalVector(vals reflect.Value) (*RSEXP, error) {
+	n := vals.Len()
+	elemType := vals.Type().Elem()
+
+	if elemType == timeType {
+		// POSIXct is the number of seconds since the Unix epoch, stored as a double
+		secs := make([]float64, n)
+		for i := range secs {
+			t := vals.Index(i).Interface().(time.Time)
+			// UnixNano would overflow for times far from 1970, so add the seconds and nanoseconds separately
+			secs[i] = float64(t.Unix()) + float64(t.Nanosecond())/1e9
+		}
+		var ps ProtectStack
+		defer ps.Unprotect()
+		out := ps.Protect(NumericToRSEXP(secs))
+		class := ps.Protect(CharacterToRSEXP([]string{"POSIXct", "POSIXt"}))
+		C.setAttrib(*out, C.R_ClassSymbol, *class)
+		return out, nil
+	}
+
+	switch elemType.Kind() {
+	case reflect.Float32, reflect.Float64:
+		floats := make([]float64, n)
+		for i := range floats {
+			floats[i] = vals.Index(i).Float()
+		}
+		return NumericToRSEXP(floats), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		ints := make([]int64, n)
+		for i := range ints {
+			ints[i] = vals.Index(i).Int()
+		}
+		return IntegerToRSEXP(ints)
+	case reflect.String:
+		strs := make([]string, n)
+		for i := range strs {
+			strs[i] = vals.Index(i).String()
+		}
+		return CharacterToRSEXP(strs), nil
+	case reflect.Bool:
+		bools := make([]bool, n)
+		for i := range bools {
+			bools[i] = vals.Index(i).Bool()
+		}
+		return LogicalToRSEXP(bools), nil
+	}
+
+	return nil, fmt.Errorf("%w: can't make an R vector of %v", UnsupportedType, elemType)
+}
+
+// unmarshalVector reads an R vector into a new slice with elements of the given type, which must be a type for which
+// isColumnType is true. Go has no notion of NA, so NA values are left as the zero value.
+func unmarshalVector(r RSEXP, elemType reflect.Type) (reflect.Value, error) {
+	n := LENGTH(r)
+	vals := reflect.MakeSlice(reflect.SliceOf(elemType), n, n)
+
+	if elemType == timeType {
+		// Dates are the number of days since the Unix epoch, while POSIXct is the number of seconds
+		var secsPerUnit float64
+		switch {
+		case C.isPOSIXct(r) != 0:
+			secsPerUnit = 1
+		case C.isDate(r) != 0:
+			secsPerUnit = 24 * 60 * 60
+		default:
+			return vals, TypeMismatch
+		}
+		units, na, err := AsNumericNA[float64](r)
+		if err != nil {
+			return vals, err
+		}
+		for i, u := range units {
+			if na[i] {
+				continue
+			}
+			secs, frac := math.Modf(u * secsPerUnit)
+			t := time.Unix(int64(secs), int64(frac*1e9)).UTC()
+			vals.Index(i).Set(reflect.ValueOf(t))
+		}
+		return vals, nil
+	}
+
+	switch elemType.Kind() {
+	case reflect.Float32, reflect.Float64:
+		floats, na, err := AsNumericNA[float64](r)
+		if err != nil {
+			return vals, err
+		}
+		for i, f := range floats {
+			if !na[i] {
+				vals.Index(i).SetFloat(f)
+			}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// doubles are read as they are, so that fractions and huge values can be caught instead of truncated
+		nums, na, err := AsNumericNA[float64](r)
+		if err != nil {
+			return vals, err
+		}
+		for i, f := range nums {
+			if na[i] {
+				continue
+			}
+			n, err := wholeNumber(i, f)
+			if err != nil {
+				return vals, err
+			}
+			if vals.Index(i).OverflowInt(n) {
+				return vals, fmt.Errorf("%w: element %d is %v, which doesn't fit in %v", IntegerOverflow, i, f, elemType)
+			}
+			vals.Index(i).SetInt(n)
+		}
+	case reflect.String:
+		strs, na, err := AsCharacterNA[string](r)
+		if err != nil {
+			return vals, err
+		}
+		for i, s := range strs {
+			if !na[i] {
+				vals.Index(i).SetString(s)
+			}
+		}
+	case reflect.Bool:
+		bools, _, err := AsLogical(r)
+		if err != nil {
+			return vals, err
+		}
+		// NA values are already false in the data slice
+		for i, b := range bools {
+			vals.Index(i).SetBool(b)
+		}
+	default:
+		return vals, fmt.Errorf("%w: can't read an R vector into %v", UnsupportedType, elemType)
+	}
+
+	return vals, nil
+}
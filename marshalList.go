@@ -0,0 +1,320 @@
+package rgo
+
+/*
+#define USE_RINTERNALS
+#include <Rinternals.h>
+*/
+import "C"
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// rsexpReflectType is the reflected type of RSEXP. RSEXPs inside of a map or struct are passed through to R unchanged.
+var rsexpReflectType = reflect.TypeOf(RSEXP(nil))
+
+// MarshalList creates an R named list from a Go map with string keys or a struct. Maps and structs inside of the input
+// become nested named lists, so that a Go value like
+//
+//	type Config struct {
+//		Name    string             `rgo:"name"`
+//		Weights []float64          `rgo:"weights"`
+//		Limits  map[string]float64 `rgo:"limits"`
+//	}
+//
+// becomes list(name = ..., weights = c(...), limits = list(...)) in R. The elements of a named list made from a struct
+// are in the same order as the fields, and are named the same way as MarshalDataFrame names columns. Because Go maps
+// have no order, the elements of a named list made from a map are sorted by name, so the output is always the same.
+//
+// Floats, ints, strings, bools, and time.Times become vectors of length 1, and slices or arrays of them become vectors
+// of the same length. Slices or arrays of anything else become unnamed lists. Nil pointers become R's NULL, and RSEXPs
+// are put into the list as they are. If any value can't be converted, an UnsupportedType error is returned. If the
+// input is not a map or a struct, the TypeMismatch error is returned.
+func MarshalList(in any) (*RSEXP, error) {
+	v := reflect.ValueOf(in)
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Map && (v.Kind() != reflect.Struct || v.Type() == timeType) {
+		return nil, TypeMismatch
+	}
+	return marshalValue(v)
+}
+
+// UnmarshalList reads an R named list into a Go map with string keys or a struct, which is the reverse of
+// MarshalList. The output must be a pointer to the map or struct. Elements are matched to struct fields by name, and
+// elements without a matching field (or fields without a matching element) are ignored. Elements which are nested
+// lists are read into nested maps, structs, slices, or arrays.
+//
+// Vectors can be read into slices, into arrays of the same length, or into single values if they have a length of 1.
+// Otherwise, a LengthMismatch error is returned. RSEXP and empty interface fields are given the element itself, without any conversion. If any
+// element can't be read into the matching Go type, a TypeMismatch or UnsupportedType error is returned.
+func UnmarshalList(r RSEXP, out any) error {
+	outVal := reflect.ValueOf(out)
+	if outVal.Kind() != reflect.Pointer || outVal.IsNil() {
+		return TypeMismatch
+	}
+	kind := outVal.Elem().Kind()
+	if kind != reflect.Map && (kind != reflect.Struct || outVal.Elem().Type() == timeType) {
+		return TypeMismatch
+	}
+	return unmarshalValue(r, outVal.Elem())
+}
+
+// marshalValue converts any supported Go value into an RSEXP, recursing into maps, structs, slices, and arrays.
+func marshalValue(v reflect.Value) (*RSEXP, error) {
+	if !v.IsValid() {
+		return rNull(), nil
+	}
+	if v.Type() == rsexpReflectType {
+		r := v.Interface().(RSEXP)
+		return &r, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return rNull(), nil
+		}
+		return marshalValue(v.Elem())
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return marshalScalar(v)
+		}
+		return marshalStruct(v)
+	case reflect.Map:
+		return marshalMap(v)
+	case reflect.Slice, reflect.Array:
+		if isColumnType(v.Type().Elem()) {
+			return marshalVector(v)
+		}
+		return marshalSlice(v)
+	}
+
+	if isColumnType(v.Type()) {
+		return marshalScalar(v)
+	}
+	return nil, fmt.Errorf("%w: can't convert %v to an R object", UnsupportedType, v.Type())
+}
+
+// marshalScalar creates an R vector of length 1 from a single value.
+func marshalScalar(v reflect.Value) (*RSEXP, error) {
+	vals := reflect.MakeSlice(reflect.SliceOf(v.Type()), 1, 1)
+	vals.Index(0).Set(v)
+	return marshalVector(vals)
+}
+
+// marshalStruct creates a named list from the fields of a struct.
+func marshalStruct(v reflect.Value) (*RSEXP, error) {
+	var names []string
+	var values []reflect.Value
+	for i := 0; i < v.NumField(); i++ {
+		name, ok := fieldName(v.Type().Field(i))
+		if !ok {
+			continue
+		}
+		names = append(names, name)
+		values = append(values, v.Field(i))
+	}
+	return marshalNamedList(names, values)
+}
+
+// marshalMap creates a named list from a map with string keys, sorted by key.
+func marshalMap(v reflect.Value) (*RSEXP, error) {
+	if v.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("%w: map keys must be strings, not %v", UnsupportedType, v.Type().Key())
+	}
+
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	names := make([]string, len(keys))
+	values := make([]reflect.Value, len(keys))
+	for i, key := range keys {
+		names[i] = key.String()
+		values[i] = v.MapIndex(key)
+	}
+	return marshalNamedList(names, values)
+}
+
+// marshalSlice creates an unnamed list from a slice of values that can't be a single R vector.
+func marshalSlice(v reflect.Value) (*RSEXP, error) {
+	var ps ProtectStack
+	defer ps.Unprotect()
+
+	elements := make([]*RSEXP, v.Len())
+	for i := range elements {
+		elem, err := marshalValue(v.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		elements[i] = ps.Protect(elem)
+	}
+	return MakeList(elements...), nil
+}
+
+// marshalNamedList converts each of the values and creates a named list from them.
+func marshalNamedList(names []string, values []reflect.Value) (*RSEXP, error) {
+	var ps ProtectStack
+	defer ps.Unprotect()
+
+	elements := make([]*RSEXP, len(values))
+	for i, value := range values {
+		elem, err := marshalValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("problem with element %s: %w", names[i], err)
+		}
+		elements[i] = ps.Protect(elem)
+	}
+	return MakeNamedList(names, elements...)
+}
+
+// unmarshalValue reads an RSEXP into any supported Go value, recursing into lists.
+func unmarshalValue(r RSEXP, dst reflect.Value) error {
+	if dst.Type() == rsexpReflectType {
+		dst.Set(reflect.ValueOf(r))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Interface:
+		// there's no way to know what the caller wants, so give them the RSEXP itself
+		if !rsexpReflectType.Implements(dst.Type()) {
+			return fmt.Errorf("%w: can't read an R object into %v", UnsupportedType, dst.Type())
+		}
+		dst.Set(reflect.ValueOf(r))
+		return nil
+	case reflect.Pointer:
+		if r == RSEXP(C.R_NilValue) {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		elem := reflect.New(dst.Type().Elem())
+		if err := unmarshalValue(r, elem.Elem()); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	case reflect.Struct:
+		if dst.Type() == timeType {
+			return unmarshalScalar(r, dst)
+		}
+		return unmarshalStruct(r, dst)
+	case reflect.Map:
+		return unmarshalMap(r, dst)
+	case reflect.Slice, reflect.Array:
+		if isColumnType(dst.Type().Elem()) {
+			vals, err := unmarshalVector(r, dst.Type().Elem())
+			if err != nil {
+				return err
+			}
+			return setElements(dst, vals)
+		}
+		return unmarshalSlice(r, dst)
+	}
+
+	if isColumnType(dst.Type()) {
+		return unmarshalScalar(r, dst)
+	}
+	return fmt.Errorf("%w: can't read an R object into %v", UnsupportedType, dst.Type())
+}
+
+// unmarshalScalar reads an R vector of length 1 into a single value.
+func unmarshalScalar(r RSEXP, dst reflect.Value) error {
+	if LENGTH(r) != 1 {
+		return fmt.Errorf("%w: can't read a vector of length %d into a single %v", LengthMismatch, LENGTH(r), dst.Type())
+	}
+	vals, err := unmarshalVector(r, dst.Type())
+	if err != nil {
+		return err
+	}
+	dst.Set(vals.Index(0))
+	return nil
+}
+
+// unmarshalStruct reads the elements of a named list into the fields of a struct with the same names.
+func unmarshalStruct(r RSEXP, dst reflect.Value) error {
+	elements, err := AsNamedList(r)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < dst.NumField(); i++ {
+		name, ok := fieldName(dst.Type().Field(i))
+		if !ok {
+			continue
+		}
+		for _, elem := range elements {
+			if elem.Name != name {
+				continue
+			}
+			if err := unmarshalValue(elem.Value, dst.Field(i)); err != nil {
+				return fmt.Errorf("problem with element %s: %w", name, err)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// unmarshalMap reads the elements of a named list into a new map, keyed by name.
+func unmarshalMap(r RSEXP, dst reflect.Value) error {
+	if dst.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("%w: map keys must be strings, not %v", UnsupportedType, dst.Type().Key())
+	}
+
+	elements, err := AsNamedList(r)
+	if err != nil {
+		return err
+	}
+
+	m := reflect.MakeMapWithSize(dst.Type(), len(elements))
+	for _, elem := range elements {
+		val := reflect.New(dst.Type().Elem()).Elem()
+		if err := unmarshalValue(elem.Value, val); err != nil {
+			return fmt.Errorf("problem with element %s: %w", elem.Name, err)
+		}
+		m.SetMapIndex(reflect.ValueOf(elem.Name).Convert(dst.Type().Key()), val)
+	}
+	dst.Set(m)
+	return nil
+}
+
+// unmarshalSlice reads each element of a list into a new slice, or into an array.
+func unmarshalSlice(r RSEXP, dst reflect.Value) error {
+	elements, err := AsList(r)
+	if err != nil {
+		return err
+	}
+
+	vals := reflect.MakeSlice(reflect.SliceOf(dst.Type().Elem()), len(elements), len(elements))
+	for i, elem := range elements {
+		if err := unmarshalValue(elem, vals.Index(i)); err != nil {
+			return fmt.Errorf("problem with element %d: %w", i, err)
+		}
+	}
+	return setElements(dst, vals)
+}
+
+// setElements sets a slice or array to the values in vals, which is a slice of the same element type. Arrays can't
+// change length, so if the lengths differ the LengthMismatch error is returned.
+func setElements(dst, vals reflect.Value) error {
+	if dst.Kind() == reflect.Slice {
+		dst.Set(vals.Convert(dst.Type()))
+		return nil
+	}
+	if vals.Len() != dst.Len() {
+		return fmt.Errorf("%w: can't read %d elements into %v", LengthMismatch, vals.Len(), dst.Type())
+	}
+	reflect.Copy(dst, vals)
+	return nil
+}
+
+// rNull returns R's NULL object as an RSEXP.
+func rNull() *RSEXP {
+	out := RSEXP(C.R_NilValue)
+	return &out
+}
@@ -0,0 +1,116 @@
+package rgo
+
+import (
+	"errors"
+	"testing"
+)
+
+type listConfig struct {
+	Name    string             `rgo:"name"`
+	Weights []float64          `rgo:"weights"`
+	Limits  map[string]float64 `rgo:"limits"`
+	Inner   *listConfig        `rgo:"inner"`
+	Skip    chan int           `rgo:"-"`
+}
+
+func TestMarshalList_TypeMismatch(t *testing.T) {
+	// only maps and structs can be named lists
+	inputs := []any{3.14, []float64{1, 2}, "hello", nil}
+	for _, in := range inputs {
+		if _, err := MarshalList(in); err != TypeMismatch {
+			t.Errorf("expected a type mismatch for %v but got %v", in, err)
+		}
+	}
+
+	// the output has to be a pointer to a map or struct
+	var notPointer listConfig
+	var notStruct []float64
+	outputs := []any{notPointer, &notStruct, nil}
+	for _, out := range outputs {
+		if err := UnmarshalList(nil, out); err != TypeMismatch {
+			t.Errorf("expected a type mismatch for %v but got %v", out, err)
+		}
+	}
+}
+
+func TestMarshalList(t *testing.T) {
+	withR(t, func(t testing.TB, ps *ProtectStack) {
+		in := listConfig{
+			Name:    "outer",
+			Weights: []float64{0.25, 0.75},
+			Limits:  map[string]float64{"upper": 10, "lower": -10},
+			Inner:   &listConfig{Name: "inner"},
+		}
+
+		list, err := MarshalList(in)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ps.Protect(list)
+
+		// the elements should be in field order, and maps should be sorted by key
+		elems, err := AsNamedList(*list)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		expectedNames := []string{"name", "weights", "limits", "inner"}
+		if len(elems) != len(expectedNames) {
+			t.Fatalf("expected elements %v but got %v", expectedNames, elems)
+		}
+		for i, elem := range elems {
+			if elem.Name != expectedNames[i] {
+				t.Errorf("expected elements %v but got %v", expectedNames, elems)
+			}
+		}
+		limits, err := AsNamedList(elems[2].Value)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if limits[0].Name != "lower" || limits[1].Name != "upper" {
+			t.Errorf("expected map elements to be sorted but got %v", limits)
+		}
+
+		var out listConfig
+		if err := UnmarshalList(*list, &out); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if out.Name != in.Name || len(out.Weights) != 2 || out.Weights[1] != 0.75 || out.Limits["lower"] != -10 {
+			t.Errorf("expected %v but got %v", in, out)
+		}
+		if out.Inner == nil || out.Inner.Name != "inner" || out.Inner.Inner != nil {
+			t.Errorf("expected nested struct %v but got %v", in.Inner, out.Inner)
+		}
+	})
+}
+
+func TestMarshalList_Arrays(t *testing.T) {
+	withR(t, func(t testing.TB, ps *ProtectStack) {
+		type arrays struct {
+			Point  [2]float64    `rgo:"point"`
+			Corner [1][2]float64 `rgo:"corner"`
+		}
+		in := arrays{Point: [2]float64{1.5, -2}, Corner: [1][2]float64{{3, 4}}}
+
+		list, err := MarshalList(in)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ps.Protect(list)
+
+		var out arrays
+		if err := UnmarshalList(*list, &out); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if out != in {
+			t.Errorf("expected %v but got %v", in, out)
+		}
+
+		// arrays can't grow or shrink to fit the vector
+		var short struct {
+			Point [3]float64 `rgo:"point"`
+		}
+		if err := UnmarshalList(*list, &short); !errors.Is(err, LengthMismatch) {
+			t.Errorf("expected a length mismatch but got %v", err)
+		}
+	})
+}
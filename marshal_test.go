@@ -0,0 +1,141 @@
+package rgo
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type marshalRecord struct {
+	ID     int       `rgo:"id"`
+	Value  float64   `rgo:"value"`
+	Label  string    // no tag, so the column is named Label
+	OK     bool      `rgo:"ok"`
+	When   time.Time `rgo:"when"`
+	notes  string    // unexported fields are skipped
+	Ignore []int     `rgo:"-"`
+}
+
+func TestStructColumns(t *testing.T) {
+	expected := []structColumn{
+		{name: "id", index: 0},
+		{name: "value", index: 1},
+		{name: "Label", index: 2},
+		{name: "ok", index: 3},
+		{name: "when", index: 4},
+	}
+
+	// both structs and pointers to structs should work
+	for _, rec := range []any{marshalRecord{}, &marshalRecord{}} {
+		columns, err := structColumns(reflect.TypeOf(rec))
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if len(columns) != len(expected) {
+			t.Fatalf("expected %d columns but got %v", len(expected), columns)
+		}
+		for i, col := range columns {
+			if col.name != expected[i].name || col.index != expected[i].index {
+				t.Errorf("expected column %v but got %v", expected[i], col)
+			}
+		}
+	}
+
+	// an unsupported field type should be an error, unless it's skipped
+	type badRecord struct {
+		Values []float64
+	}
+	if _, err := structColumns(reflect.TypeOf(badRecord{})); !errors.Is(err, UnsupportedType) {
+		t.Errorf("expected an unsupported type error but got %v", err)
+	}
+
+	// a struct without any columns can't make a data frame
+	type emptyRecord struct {
+		hidden int
+		Skip   int `rgo:"-"`
+	}
+	if _, err := structColumns(reflect.TypeOf(emptyRecord{})); !errors.Is(err, UnsupportedType) {
+		t.Errorf("expected an unsupported type error but got %v", err)
+	}
+
+	// and only structs can be rows
+	if _, err := structColumns(reflect.TypeOf(3.14)); err != TypeMismatch {
+		t.Errorf("expected a type mismatch but got %v", err)
+	}
+}
+
+func TestMarshalDataFrameNilRow(t *testing.T) {
+	// the nil row is caught before anything is sent to R
+	in := []*marshalRecord{{ID: 1}, nil, {ID: 3}}
+	_, err := MarshalDataFrame(in)
+	if !errors.Is(err, TypeMismatch) {
+		t.Fatalf("expected a type mismatch but got %v", err)
+	}
+	if !strings.Contains(err.Error(), "row 1") {
+		t.Errorf("expected the error to name row 1 but got %v", err)
+	}
+}
+
+func TestMarshalDataFrame(t *testing.T) {
+	withR(t, func(t testing.TB, ps *ProtectStack) {
+		when := time.Date(2022, 4, 5, 12, 30, 0, 0, time.UTC)
+		in := []marshalRecord{
+			{ID: 1, Value: 1.1, Label: "a", OK: true, When: when},
+			{ID: 2, Value: 2.2, Label: "b", OK: false, When: when.Add(time.Hour)},
+		}
+
+		df, err := MarshalDataFrame(in)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ps.Protect(df)
+
+		var out []*marshalRecord
+		if err := UnmarshalDataFrame(*df, &out); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if len(out) != len(in) {
+			t.Fatalf("expected %d rows but got %d", len(in), len(out))
+		}
+		for i, rec := range out {
+			if rec.ID != in[i].ID || rec.Value != in[i].Value || rec.Label != in[i].Label || rec.OK != in[i].OK ||
+				!rec.When.Equal(in[i].When) {
+				t.Errorf("expected row %v but got %v", in[i], *rec)
+			}
+		}
+
+		// the input has to be a slice
+		if _, err := MarshalDataFrame(in[0]); err != TypeMismatch {
+			t.Errorf("expected a type mismatch but got %v", err)
+		}
+		if err := UnmarshalDataFrame(*df, out); err != TypeMismatch {
+			t.Errorf("expected a type mismatch but got %v", err)
+		}
+	})
+}
+
+func TestUnmarshalVectorIntegers(t *testing.T) {
+	withR(t, func(t testing.TB, ps *ProtectStack) {
+		tests := []struct {
+			in      []float64
+			typ     reflect.Type
+			wantErr error
+		}{
+			{[]float64{1, -128, 127}, reflect.TypeOf(int8(0)), nil},
+			{[]float64{1, 300}, reflect.TypeOf(int8(0)), IntegerOverflow},
+			{[]float64{1, 1 << 40}, reflect.TypeOf(int32(0)), IntegerOverflow},
+			{[]float64{1, 1e300}, reflect.TypeOf(int64(0)), IntegerOverflow},
+			{[]float64{1.5}, reflect.TypeOf(0), TypeMismatch},
+		}
+
+		for _, test := range tests {
+			r := ps.Protect(NumericToRSEXP(test.in))
+			_, err := unmarshalVector(*r, test.typ)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("reading %v as %v: expected error %v but got %v", test.in, test.typ, test.wantErr, err)
+			}
+		}
+	})
+}
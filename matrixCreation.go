@@ -61,6 +61,8 @@ func CopyMatrix(in Matrix) (out Matrix) {
 	for i, f := range in.Data {
 		out.Data[i] = f
 	}
+	out.RowNames = copyNames(in.RowNames)
+	out.ColNames = copyNames(in.ColNames)
 	return out
 }
 
@@ -69,6 +71,8 @@ func CopyMatrix(in Matrix) (out Matrix) {
 func (m *Matrix) CreateTranspose() *Matrix {
 	// create the matrix with the new dimensions
 	mt := Matrix{Nrow: m.Ncol, Ncol: m.Nrow, Data: make([]float64, len(m.Data))}
+	mt.RowNames = copyNames(m.ColNames)
+	mt.ColNames = copyNames(m.RowNames)
 
 	// each row of the new matrix is a column of the old one
 	for i := 0; i < m.Ncol; i++ {
@@ -77,3 +81,13 @@ func (m *Matrix) CreateTranspose() *Matrix {
 	}
 	return &mt
 }
+
+// copyNames copies a slice of row or column names, keeping nil as nil so that a matrix without names stays that way.
+func copyNames(names []string) []string {
+	if names == nil {
+		return nil
+	}
+	out := make([]string, len(names))
+	copy(out, names)
+	return out
+}
@@ -15,7 +15,8 @@ func dotProduct(a, b []float64) (f float64, err error) {
 // MatrixMultiply performs a matrix multiplication of two matrices. This is not an element-wise multiplication, but
 // a true multiplication as defined in elementary linear algebra. In matrix multiplication, order
 // matters. Two matrices A and B can only be multiplied if A has the same number of rows as B has number of columns. If
-// the dimensions of the input matrices do not allow for a multiplication, a SizeMismatch error is returned.
+// the dimensions of the input matrices do not allow for a multiplication, a SizeMismatch error is returned. Like in R,
+// the result takes its row names from A and its column names from B.
 func MatrixMultiply(A, B *Matrix) (C *Matrix, err error) {
 	// checks to ensure matrix quality
 	if !(A.isSizeValid() && B.isSizeValid()) {
@@ -30,6 +31,8 @@ func MatrixMultiply(A, B *Matrix) (C *Matrix, err error) {
 	if err != nil {
 		return C, err
 	}
+	C.RowNames = copyNames(A.RowNames)
+	C.ColNames = copyNames(B.ColNames)
 
 	for i := 0; i < A.Nrow; i++ { // this indexes the row
 		for j := 0; j < B.Ncol; j++ { // this indexes the column
@@ -56,7 +59,8 @@ func MatrixMultiply(A, B *Matrix) (C *Matrix, err error) {
 }
 
 // MatrixAdd adds two matrices. Matrix addition is done by adding each element of the two matrices together, so
-// they must be of identical size. If they are not, a SizeMismatch error will be returned.
+// they must be of identical size. If they are not, a SizeMismatch error will be returned. Like in R, the result keeps
+// the row and column names of A.
 func MatrixAdd(A, B *Matrix) (C *Matrix, err error) {
 	// checks to ensure matrix quality
 	if !(A.isSizeValid() && B.isSizeValid()) {
@@ -67,6 +71,8 @@ func MatrixAdd(A, B *Matrix) (C *Matrix, err error) {
 	}
 
 	C = &Matrix{Nrow: A.Nrow, Ncol: B.Ncol, Data: make([]float64, len(A.Data))}
+	C.RowNames = copyNames(A.RowNames)
+	C.ColNames = copyNames(A.ColNames)
 
 	for i, av := range A.Data {
 		C.Data[i] = av + B.Data[i]
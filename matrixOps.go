@@ -2,7 +2,7 @@ package rgo
 
 // AreMatricesEqual returns true if the input matrices are of the same dimension and have identical data vectors. It's
 // important to note that this function uses strict equality - even if elements of two matrices differ by floating
-// point error, it will return false.
+// point error, it will return false. Row and column names are not compared.
 func AreMatricesEqual(A, B Matrix) bool {
 	if A.Ncol != B.Ncol {
 		return false
@@ -102,7 +102,8 @@ func (m *Matrix) SetRow(ind int, data []float64) error {
 }
 
 // AppendRow appends a row onto an existing matrix and updates the dimension metadata accordingly. If the length of the
-// provided row is not equal to the number of columns in the matrix, it will return a SizeMismatch error.
+// provided row is not equal to the number of columns in the matrix, it will return a SizeMismatch error. If the matrix
+// has row names, the new row's name is empty, which is what R does when binding an unnamed row.
 func (m *Matrix) AppendRow(data []float64) error {
 	if !m.isSizeValid() {
 		return ImpossibleMatrix
@@ -117,6 +118,9 @@ func (m *Matrix) AppendRow(data []float64) error {
 
 	//now we can safely index the Ncol field without lying
 	m.Nrow++
+	if m.RowNames != nil {
+		m.RowNames = append(m.RowNames, "")
+	}
 
 	//loop through the end of each row and insert the data
 	var sliceind int = 0 //tracks data index
@@ -178,7 +182,8 @@ func (m *Matrix) SetCol(ind int, data []float64) error {
 }
 
 // AppendCol appends a column onto an existing matrix and updates the dimension metadata accordingly. If the provided
-// data column is not equal to the number of rows in the matrix, it will return a SizeMismatch error.
+// data column is not equal to the number of rows in the matrix, it will return a SizeMismatch error. If the matrix has
+// column names, the new column's name is empty.
 func (m *Matrix) AppendCol(data []float64) error {
 	if !m.isSizeValid() {
 		return ImpossibleMatrix
@@ -190,6 +195,29 @@ func (m *Matrix) AppendCol(data []float64) error {
 	// because column indices are adjacent, we just need to use append
 	m.Data = append(m.Data, data...)
 	m.Ncol++
+	if m.ColNames != nil {
+		m.ColNames = append(m.ColNames, "")
+	}
+	return nil
+}
+
+// SetNames sets the row and column names of the matrix. Either can be nil, which removes the names for that dimension.
+// Otherwise, if the number of names doesn't match the number of rows or columns, it will return a LengthMismatch error
+// and the names are left unchanged. The names are copied, so the input slices can be changed without altering the
+// matrix.
+func (m *Matrix) SetNames(rowNames, colNames []string) error {
+	if !m.isSizeValid() {
+		return ImpossibleMatrix
+	}
+	if rowNames != nil && len(rowNames) != m.Nrow {
+		return LengthMismatch
+	}
+	if colNames != nil && len(colNames) != m.Ncol {
+		return LengthMismatch
+	}
+
+	m.RowNames = copyNames(rowNames)
+	m.ColNames = copyNames(colNames)
 	return nil
 }
 
@@ -1,6 +1,7 @@
 package rgo
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -422,3 +423,42 @@ func TestMatrix_SetInd(t *testing.T) {
 		t.Errorf("did not set index correctly. got: %v, expected: %v", testMat, finalMat)
 	}
 }
+
+func TestMatrix_SetNames(t *testing.T) {
+	testMat := CopyMatrix(startingMatrix)
+
+	// the names have to match the dimensions
+	err := testMat.SetNames([]string{"a", "b"}, nil)
+	if err != LengthMismatch {
+		t.Errorf("was supposed to get Length Mismatch, but got this instead: %v", err)
+	}
+	err = testMat.SetNames(nil, []string{"x", "y", "z"})
+	if err != LengthMismatch {
+		t.Errorf("was supposed to get Length Mismatch, but got this instead: %v", err)
+	}
+	if testMat.RowNames != nil || testMat.ColNames != nil {
+		t.Error("failed call to SetNames changed the names anyway")
+	}
+
+	rowNames := []string{"a", "b", "c"}
+	err = testMat.SetNames(rowNames, []string{"x", "y"})
+	if err != nil {
+		t.Errorf("was supposed to set names but got this error instead: %v", err)
+	}
+	rowNames[0] = "changed"
+	if testMat.RowNames[0] != "a" {
+		t.Error("changing the input slice changed the matrix's names")
+	}
+
+	// names follow the data through a transpose
+	tr := testMat.CreateTranspose()
+	if !reflect.DeepEqual(tr.RowNames, testMat.ColNames) || !reflect.DeepEqual(tr.ColNames, testMat.RowNames) {
+		t.Errorf("transpose did not swap names. got rows %v and cols %v", tr.RowNames, tr.ColNames)
+	}
+
+	// appended rows get an empty name so the names still fit
+	testMat.AppendRow([]float64{7.7, 8.8})
+	if !testMat.areNamesValid() || testMat.RowNames[3] != "" {
+		t.Errorf("appending a row broke the row names: %v", testMat.RowNames)
+	}
+}
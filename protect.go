@@ -0,0 +1,79 @@
+package rgo
+
+/*
+#define USE_RINTERNALS
+#include <Rinternals.h>
+SEXP gctortureCall(int on) {
+	// symbols are never garbage collected and ScalarLogical returns R's shared TRUE or FALSE, so nothing here needs
+	// protecting while the call is built
+	return lang2(install("gctorture"), ScalarLogical(on));
+}
+*/
+import "C"
+
+// ProtectStack keeps track of RSEXPs that have been protected from R's garbage collector, so that they can all be
+// unprotected at once when they are no longer needed.
+//
+// R's garbage collector can run whenever R allocates memory, and it frees anything that isn't reachable from an R
+// variable or protected. Every RSEXP created in Go, such as those returned by NumericToRSEXP, starts out unprotected.
+// That's fine if it's immediately sent back to R, but creating a second RSEXP before the first one is used (for
+// example, to put them both in a list) gives R a chance to collect the first one. A ProtectStack prevents this:
+//
+//	var ps rgo.ProtectStack
+//	defer ps.Unprotect()
+//
+//	a := ps.Protect(rgo.NumericToRSEXP(floats))
+//	b := ps.Protect(rgo.CharacterToRSEXP(strs))
+//	list := rgo.MakeList(a, b)
+//
+// R's protection stack is last in, first out, so a ProtectStack should be unprotected in the same function that
+// created it, after any other ProtectStack created since. The zero value is an empty ProtectStack ready to use.
+type ProtectStack struct {
+	// n is the number of SEXPs this stack has pushed onto R's protection stack
+	n int
+}
+
+// Protect protects the input RSEXP from R's garbage collector until Unprotect is called, and returns the same RSEXP so
+// that it can wrap a constructor call.
+func (p *ProtectStack) Protect(r *RSEXP) *RSEXP {
+	p.protect(C.SEXP(*r))
+	return r
+}
+
+// Unprotect removes every RSEXP this ProtectStack has protected from R's protection stack. Afterwards, the
+// ProtectStack is empty and can be used again.
+func (p *ProtectStack) Unprotect() {
+	if p.n == 0 {
+		return
+	}
+	C.Rf_unprotect(C.int(p.n))
+	p.n = 0
+}
+
+// protect is the internal version of Protect, which works on C.SEXPs so it can be used in the middle of building one.
+func (p *ProtectStack) protect(s C.SEXP) C.SEXP {
+	p.n++
+	return C.Rf_protect(s)
+}
+
+// protectAll protects each of the input RSEXPs. Constructors that take RSEXPs as inputs use it so that the inputs can't
+// be collected while the constructor allocates the output.
+func (p *ProtectStack) protectAll(in []*RSEXP) {
+	for _, r := range in {
+		p.protect(C.SEXP(*r))
+	}
+}
+
+// setGCTorture turns R's gctorture mode on or off, in which the garbage collector runs on every allocation. It is used
+// to make sure Rgo's constructors protect everything they need to, because any mistake will show up right away.
+func setGCTorture(on bool) {
+	var ps ProtectStack
+	defer ps.Unprotect()
+
+	var onInt C.int
+	if on {
+		onInt = 1
+	}
+	call := ps.protect(C.gctortureCall(onInt))
+	C.Rf_eval(call, C.R_BaseEnv)
+}
@@ -0,0 +1,90 @@
+package rgo_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/EMurray16/rgo/v2"
+)
+
+// withGCTorture runs the test function on R's thread with R's garbage collector running on every allocation, so that
+// anything left unprotected gets collected right away. These tests need a running R interpreter, so they are skipped
+// otherwise.
+func withGCTorture(t *testing.T, f func(t testing.TB, ps *rgo.ProtectStack)) {
+	rgo.WithR(t, func(t testing.TB, ps *rgo.ProtectStack) {
+		rgo.SetGCTorture(true)
+		defer rgo.SetGCTorture(false)
+		f(t, ps)
+	})
+}
+
+func TestCharacterToRSEXP_GCTorture(t *testing.T) {
+	withGCTorture(t, func(t testing.TB, ps *rgo.ProtectStack) {
+		strs := make([]string, 100)
+		for i := range strs {
+			strs[i] = fmt.Sprintf("string number %d", i)
+		}
+		r := ps.Protect(rgo.CharacterToRSEXP(strs))
+
+		out, err := rgo.AsCharacter[string](*r)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		for i, s := range out {
+			if s != strs[i] {
+				t.Errorf("expected %q at index %d but got %q", strs[i], i, s)
+			}
+		}
+	})
+}
+
+func TestMatrixToRSEXP_GCTorture(t *testing.T) {
+	withGCTorture(t, func(t testing.TB, ps *rgo.ProtectStack) {
+		in := rgo.Matrix{Nrow: 3, Ncol: 2, Data: []float64{1.1, 2.2, 3.3, 4.4, 5.5, 6.6}}
+		r, err := rgo.MatrixToRSEXP(in)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ps.Protect(r)
+
+		out, err := rgo.AsMatrix(*r)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if !rgo.AreMatricesEqual(out, in) {
+			t.Errorf("expected %v but got %v", in, out)
+		}
+	})
+}
+
+func TestMakeNamedList_GCTorture(t *testing.T) {
+	withGCTorture(t, func(t testing.TB, ps *rgo.ProtectStack) {
+		a := ps.Protect(rgo.NumericToRSEXP([]float64{1.1, 2.2}))
+		b := ps.Protect(rgo.CharacterToRSEXP([]string{"a", "b", "c"}))
+
+		list, err := rgo.MakeNamedList([]string{"a", "b"}, a, b)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ps.Protect(list)
+		if rgo.LENGTH(*list) != 2 {
+			t.Errorf("expected a list of length 2 but got %d", rgo.LENGTH(*list))
+		}
+	})
+}
+
+func TestMakeDataFrame_GCTorture(t *testing.T) {
+	withGCTorture(t, func(t testing.TB, ps *rgo.ProtectStack) {
+		a := ps.Protect(rgo.NumericToRSEXP([]float64{1.1, 2.2, 3.3}))
+		b := ps.Protect(rgo.CharacterToRSEXP([]string{"a", "b", "c"}))
+
+		df, err := rgo.MakeDataFrame(nil, []string{"num", "chr"}, a, b)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ps.Protect(df)
+		if rgo.LENGTH(*df) != 2 {
+			t.Errorf("expected a data frame with 2 columns but got %d", rgo.LENGTH(*df))
+		}
+	})
+}
@@ -0,0 +1,30 @@
+package rgo
+
+/*
+#define USE_RINTERNALS
+#include <Rinternals.h>
+*/
+import "C"
+
+// AsRaw extracts the data from a raw vector as a byte slice. Raw vectors hold binary data, such as serialized or
+// compressed objects, and can contain any byte including NUL. The data is copied in a single bulk copy, and the
+// resulting slice is a new copy that can be modified independently. To avoid the copy, use RawView. If the data in
+// the RSEXP is not a raw vector, the TypeMismatch error is returned.
+func AsRaw(r RSEXP) ([]byte, error) {
+	view, err := RawView(r)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(view))
+	copy(out, view)
+	return out, nil
+}
+
+// RawToRSEXP converts a byte slice into a C.SEXP, represented by the returned RSEXP data. The R representation will
+// have the same data as the input slice and be the RAWSXP type (aka a raw vector in R). Unlike CharacterToRSEXP, the
+// data is kept exactly as it is, including any NUL bytes.
+func RawToRSEXP(in []byte) *RSEXP {
+	out := RSEXP(C.allocVector(C.RAWSXP, C.long(len(in))))
+	copy(rawView(out), in)
+	return &out
+}
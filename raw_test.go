@@ -0,0 +1,39 @@
+package rgo
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRawToRSEXP(t *testing.T) {
+	withR(t, func(t testing.TB, ps *ProtectStack) {
+		// binary data can contain anything, including NUL bytes
+		in := []byte{0x00, 0xff, 'g', 'o', 0x00, 0x7f}
+
+		r := ps.Protect(RawToRSEXP(in))
+
+		out, err := AsRaw(*r)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if !bytes.Equal(in, out) {
+			t.Errorf("expected %v but got %v", in, out)
+		}
+
+		// the copy should be independent of the R object, while the view is not
+		out[0] = 42
+		view, err := RawView(*r)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if view[0] != 0 {
+			t.Error("changing the copy changed the R object")
+		}
+
+		// strings aren't raw vectors
+		strs := ps.Protect(CharacterToRSEXP([]string{"go"}))
+		if _, err := AsRaw(*strs); err != TypeMismatch {
+			t.Errorf("expected a type mismatch but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package rgo
+
+import "testing"
+
+// rThread runs a function on the thread R is running on. It's set by TestMain when it starts R, which it does when
+// R_HOME is set, and is nil otherwise.
+var rThread func(func() error) error
+
+// withR runs a test which needs R on R's thread, with a ProtectStack that is unprotected when the test is done. If R
+// isn't running, the test is skipped.
+func withR(tb testing.TB, f func(t testing.TB, ps *ProtectStack)) {
+	tb.Helper()
+	if rThread == nil {
+		tb.Skip("R is not running, since R_HOME is not set")
+	}
+
+	rt := &rThreadTB{TB: tb}
+	err := rThread(func() error {
+		defer func() {
+			// stopping the test is expected, but anything else is a real panic for rThread to report
+			if p := recover(); p != nil && p != errStopTest {
+				panic(p)
+			}
+		}()
+
+		var ps ProtectStack
+		defer ps.Unprotect()
+		f(rt, &ps)
+		return nil
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if rt.stop != nil {
+		rt.stop()
+	}
+}
+
+// errStopTest is the panic used to stop a test running on R's thread.
+var errStopTest = new(int)
+
+// rThreadTB is the testing.TB given to tests running on R's thread. FailNow and SkipNow end the goroutine they're
+// called on, which would take R's thread down with it, so they panic instead and withR stops the test once it's back on
+// the test's own goroutine.
+type rThreadTB struct {
+	testing.TB
+	stop func()
+}
+
+func (t *rThreadTB) FailNow() {
+	t.stop = t.TB.FailNow
+	panic(errStopTest)
+}
+
+func (t *rThreadTB) Fatal(args ...any) {
+	t.TB.Helper()
+	t.TB.Error(args...)
+	t.FailNow()
+}
+
+func (t *rThreadTB) Fatalf(format string, args ...any) {
+	t.TB.Helper()
+	t.TB.Errorf(format, args...)
+	t.FailNow()
+}
+
+func (t *rThreadTB) SkipNow() {
+	t.stop = t.TB.SkipNow
+	panic(errStopTest)
+}
+
+func (t *rThreadTB) Skip(args ...any) {
+	t.TB.Helper()
+	t.TB.Log(args...)
+	t.SkipNow()
+}
+
+func (t *rThreadTB) Skipf(format string, args ...any) {
+	t.TB.Helper()
+	t.TB.Logf(format, args...)
+	t.SkipNow()
+}
@@ -0,0 +1,152 @@
+package rgo
+
+/*
+#define USE_RINTERNALS
+#include <Rinternals.h>
+*/
+import "C"
+import "fmt"
+
+// AsTypedMatrix returns a matrix of the given type based on the input RSEXP. A matrix of float64s or ints can be
+// extracted from a double, integer, or logical matrix in R, while a matrix of bools can only be extracted from a
+// logical matrix. A vector without a dimension attribute is treated as a matrix with one column. The data returned is a
+// copy of the data contained in the RSEXP that can be modified independently. If the RSEXP is not one of the allowed
+// types, the TypeMismatch error is returned.
+//
+// A double matrix is only read as ints if every element is a whole number. A fraction or NaN returns a TypeMismatch
+// error, and a number that doesn't fit in an int returns an IntegerOverflow error, rather than being truncated.
+//
+// If the R matrix has dimnames, they are used as the matrix's row and column names. The NA slice is nil unless the
+// matrix contains at least one NA.
+func AsTypedMatrix[t RMatrixData](r RSEXP) (out TypedMatrix[t], err error) {
+	rsexpType := TYPEOF(r)
+
+	switch data := any(&out.Data).(type) {
+	case *[]float64:
+		*data, out.NA, err = asMatrixNumbers[float64](r, rsexpType)
+	case *[]int:
+		*data, out.NA, err = asMatrixInts(r, rsexpType)
+	case *[]bool:
+		*data, out.NA, err = AsLogical(r)
+	}
+	if err != nil {
+		return out, err
+	}
+
+	out.Nrow = int(C.nrows(r))
+	out.Ncol = int(C.ncols(r))
+
+	dimnames, err := GetDimnames(r)
+	if err != nil {
+		return out, err
+	}
+	if len(dimnames) == 2 {
+		out.RowNames, out.ColNames = dimnames[0], dimnames[1]
+	}
+
+	if !anyNA(out.NA) {
+		out.NA = nil
+	}
+	return out, nil
+}
+
+// asMatrixNumbers extracts numeric data from a double, integer, or logical RSEXP. Logicals become 1 for TRUE and 0 for
+// FALSE, the same as as.numeric in R.
+func asMatrixNumbers[t RNumeric](r RSEXP, rsexpType RSEXPTYPE) (out []t, na []bool, err error) {
+	if rsexpType != LGLSXP {
+		return AsNumericNA[t](r)
+	}
+
+	bools, na, err := AsLogical(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	out = make([]t, len(bools))
+	for i, b := range bools {
+		if b {
+			out[i] = 1
+		}
+	}
+	return out, na, nil
+}
+
+// asMatrixInts extracts ints from a double, integer, or logical RSEXP. Doubles are checked with wholeNumber, so that
+// they aren't silently truncated.
+func asMatrixInts(r RSEXP, rsexpType RSEXPTYPE) (out []int, na []bool, err error) {
+	if rsexpType != REALSXP {
+		return asMatrixNumbers[int](r, rsexpType)
+	}
+
+	floats, na, err := AsNumericNA[float64](r)
+	if err != nil {
+		return nil, nil, err
+	}
+	out = make([]int, len(floats))
+	for i, f := range floats {
+		if na[i] {
+			continue
+		}
+		n, err := wholeNumber(i, f)
+		if err != nil {
+			return nil, nil, err
+		}
+		// an int is 64 bits on the platforms R supports, but check in case it's smaller
+		if int64(int(n)) != n {
+			return nil, nil, fmt.Errorf("%w: element %d is %v, which doesn't fit in an int", IntegerOverflow, i, f)
+		}
+		out[i] = int(n)
+	}
+	return out, na, nil
+}
+
+// anyNA reports whether any element of an NA mask is true.
+func anyNA(na []bool) bool {
+	for _, isNA := range na {
+		if isNA {
+			return true
+		}
+	}
+	return false
+}
+
+// TypedMatrixToRSEXP converts a TypedMatrix into a C.SEXP, represented by the returned RSEXP data. The R
+// representation will have the same data, dimensions, and NA values as the input and be a double, integer, or logical
+// matrix to match the type of its data.
+//
+// If the matrix's dimensions don't match the length of its data, an ImpossibleMatrix error is returned. If its NA
+// slice or names don't match its data, a LengthMismatch error is returned. Because R's integers are 32 bits, an integer
+// matrix with an element that doesn't fit returns an IntegerOverflow error.
+func TypedMatrixToRSEXP[t RMatrixData](in TypedMatrix[t]) (*RSEXP, error) {
+	if !in.isSizeValid() {
+		return nil, ImpossibleMatrix
+	}
+
+	var s *RSEXP
+	var err error
+	switch data := any(in.Data).(type) {
+	case []float64:
+		s, err = NumericToRSEXPNA(data, in.NA)
+	case []int:
+		s, err = IntegerToRSEXPNA(data, in.NA)
+	case []bool:
+		s, err = LogicalToRSEXPNA(data, in.NA)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ps ProtectStack
+	defer ps.Unprotect()
+	ps.Protect(s)
+
+	if err := SetDim(*s, []int{in.Nrow, in.Ncol}); err != nil {
+		return nil, err
+	}
+	if in.RowNames != nil || in.ColNames != nil {
+		if err := SetDimnames(*s, [][]string{in.RowNames, in.ColNames}); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
@@ -0,0 +1,100 @@
+package rgo
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTypedMatrixToRSEXP(t *testing.T) {
+	// size and NA checks happen before anything is sent to R
+	impossible := TypedMatrix[int]{Nrow: 2, Ncol: 2, Data: []int{1, 2, 3}}
+	if _, err := TypedMatrixToRSEXP(impossible); err != ImpossibleMatrix {
+		t.Errorf("expected an impossible matrix error but got %v", err)
+	}
+
+	withR(t, func(t testing.TB, ps *ProtectStack) {
+		ints := TypedMatrix[int]{Nrow: 3, Ncol: 2, Data: []int{1, 2, 3, 4, 0, 6}, NA: []bool{false, false, false, false, true, false},
+			RowNames: []string{"a", "b", "c"}}
+		r, err := TypedMatrixToRSEXP(ints)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ps.Protect(r)
+		if TYPEOF(*r) != INTSXP {
+			t.Errorf("expected an integer matrix but got type %v", TYPEOF(*r))
+		}
+		outInts, err := AsTypedMatrix[int](*r)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(ints, outInts) {
+			t.Errorf("expected %v but got %v", ints, outInts)
+		}
+
+		// integer matrices can be read as float64 matrices too, with NA kept as NA
+		floats, err := AsMatrix(*r)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if floats.Data[1] != 2 || !isRealNA(floats.Data[4]) {
+			t.Errorf("integer matrix was not converted correctly: %v", floats.Data)
+		}
+
+		bools := TypedMatrix[bool]{Nrow: 2, Ncol: 2, Data: []bool{true, false, false, true}}
+		r, err = TypedMatrixToRSEXP(bools)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ps.Protect(r)
+		outBools, err := AsTypedMatrix[bool](*r)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(bools, outBools) {
+			t.Errorf("expected %v but got %v", bools, outBools)
+		}
+
+		// a logical matrix can be read as numbers, but not the other way around
+		adjacency, err := AsTypedMatrix[int](*r)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(adjacency.Data, []int{1, 0, 0, 1}) {
+			t.Errorf("expected 1s and 0s but got %v", adjacency.Data)
+		}
+		r, err = MatrixToRSEXP(startingMatrix)
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ps.Protect(r)
+		if _, err := AsTypedMatrix[bool](*r); err != TypeMismatch {
+			t.Errorf("expected a type mismatch but got %v", err)
+		}
+
+		// doubles are only read as ints when nothing would be lost
+		r, err = MatrixToRSEXP(Matrix{Nrow: 1, Ncol: 3, Data: []float64{1, 2.5, math.NaN()}})
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ps.Protect(r)
+		if _, err := AsTypedMatrix[int](*r); !errors.Is(err, TypeMismatch) {
+			t.Errorf("expected a type mismatch but got %v", err)
+		}
+		r, err = MatrixToRSEXP(Matrix{Nrow: 1, Ncol: 2, Data: []float64{-3, 4}})
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		ps.Protect(r)
+		if whole, err := AsTypedMatrix[int](*r); err != nil || !reflect.DeepEqual(whole.Data, []int{-3, 4}) {
+			t.Errorf("expected [-3 4] but got %v (error %v)", whole.Data, err)
+		}
+
+		// names that don't fit are an error
+		ints.ColNames = []string{"x"}
+		if _, err := TypedMatrixToRSEXP(ints); !errors.Is(err, LengthMismatch) {
+			t.Errorf("expected a length mismatch but got %v", err)
+		}
+	})
+}
@@ -9,17 +9,42 @@ import (
 type RSEXPTYPE int
 
 // These constants are enumerations of the SEXPTYPEs that are part of R's internals. There are about 2 dozen in all,
-// Rgo only supports 5 of them.
+// Rgo only supports 13 of them.
 const (
+	// CLOSXP is a function written in R, which is called a closure.
+	CLOSXP RSEXPTYPE = 3
+
+	// ENVSXP is an environment, which holds variables by name, like the global environment or the inside of a function.
+	ENVSXP RSEXPTYPE = 4
+
+	// SPECIALSXP and BUILTINSXP are functions built into R and written in C, like `if` and sum. A special function
+	// gets its arguments without evaluating them first, while a builtin gets them evaluated.
+	SPECIALSXP RSEXPTYPE = 7
+	BUILTINSXP RSEXPTYPE = 8
+
 	CHARSXP RSEXPTYPE = 9
+
+	// LGLSXP is a logical vector. R stores logicals as 32 bit integers so that they can hold TRUE, FALSE, or NA.
+	LGLSXP RSEXPTYPE = 10
+
 	INTSXP  RSEXPTYPE = 13
 	REALSXP RSEXPTYPE = 14
 
+	// CPLXSXP is a vector of complex numbers, each of which is a pair of doubles.
+	CPLXSXP RSEXPTYPE = 15
+
 	// A STRSXP is a vector of strings, where each element points to a CHARSXP.
 	STRSXP RSEXPTYPE = 16
 
 	// VECSXP is a list, which is not obvious from the name. Each element of a VECSXP is a SEXP and can be of any type.
 	VECSXP RSEXPTYPE = 19
+
+	// EXTPTRSXP is an external pointer, which R uses to hold on to data that lives outside of R. Rgo uses them to keep
+	// Go values alive between calls.
+	EXTPTRSXP RSEXPTYPE = 22
+
+	// RAWSXP is a vector of raw bytes, which R uses for binary data.
+	RAWSXP RSEXPTYPE = 24
 )
 
 // RCharacter is a type parameter of Go types that map well onto R's character type, which is a string and a byte slice.
@@ -35,6 +60,17 @@ type RNumeric interface {
 		~int | ~int8 | ~int16 | ~int32 | ~int64
 }
 
+// RMatrixData is a type parameter of the Go types that can be the data of a TypedMatrix, which are the types that
+// match R's double, integer, and logical matrices.
+type RMatrixData interface {
+	float64 | int | bool
+}
+
+// RComplex is a type parameter of Go types that map well onto R's complex type, which are the complex types.
+type RComplex interface {
+	~complex128 | ~complex64
+}
+
 // TypeMismatch is most often returned from an AsX method when the caller tries to extract the incorrect type from
 // a SEXP, or when they try to create a SEXP of the wrong type using a Go slice.
 var TypeMismatch = errors.New("input SEXP type does not match desired output type")
@@ -46,6 +82,24 @@ var UnsupportedType = errors.New("type provided is not currently supported in Rg
 // NotASEXP is returned by NewRSEXP or ExportRSEXP when it cannot coerce the input object into a *C.SEXP.
 var NotASEXP = errors.New("non-SEXP object provided to a function that needs a SEXP")
 
+// IntegerOverflow is returned when a Go number can't be represented as an R integer. R integers are 32 bits, and the
+// smallest 32 bit integer is reserved for NA, so values outside of that range cannot be sent to R as integers.
+var IntegerOverflow = errors.New("value is outside the range of R's integer type")
+
+// GoPanic is wrapped by the error Guard makes from a panic, so that it can be told apart from a normal error.
+var GoPanic = errors.New("Go function panicked")
+
+// VariableNotFound is returned when a variable doesn't exist in an R environment.
+var VariableNotFound = errors.New("variable not found in environment")
+
+// ReleasedPointer is returned when a Go value is requested from an external pointer that has already been released.
+var ReleasedPointer = errors.New("external pointer has already been released")
+
+// RError is returned when R itself raises an error while Rgo is asking it to do something, such as setting an invalid
+// attribute. Errors from evaluating R code, like those from Call and Eval, include R's message, while errors from
+// SetAttr and Assign only say which attribute or variable couldn't be set.
+var RError = errors.New("R returned an error")
+
 // All matrix and data frame operations check inputs for validity and will return errors where applicable.
 var (
 	ImpossibleMatrix = errors.New("matrix size and underlying data length are not compatible")
@@ -69,15 +123,113 @@ var (
 //
 // Matrix data is accessed using 0-based indexing, which is natural in Go but differs from R. For example, the 0th
 // row in the example matrix is [1.1, 4.4], while the "1st" row is [2.2, 5.5].
+//
+// A Matrix can also have row and column names, which mirror the dimnames of a matrix in R. Either can be nil, meaning
+// that dimension has no names. Otherwise, there must be one name for each row or column.
 type Matrix struct {
 	// The Matrix header - two integers which specify its dimension
 	Nrow, Ncol int
 
 	// The data in a matrix is represented as a single slice of data
 	Data []float64
+
+	// Optional names for each row and column
+	RowNames, ColNames []string
 }
 
 // isSizeValid checks to make sure a matrix's dimensions and data length match.
 func (m *Matrix) isSizeValid() bool {
 	return m.Nrow*m.Ncol == len(m.Data)
 }
+
+// areNamesValid checks to make sure a matrix's row and column names, if it has them, match its dimensions.
+func (m *Matrix) areNamesValid() bool {
+	return (m.RowNames == nil || len(m.RowNames) == m.Nrow) && (m.ColNames == nil || len(m.ColNames) == m.Ncol)
+}
+
+// ComplexMatrix is the same as a Matrix, except that its data are complex numbers. It mirrors a complex matrix in R, and
+// its data are organized the same way as a Matrix, so that column indices are together.
+type ComplexMatrix struct {
+	// The number of rows and columns
+	Nrow, Ncol int
+
+	// The complex values, one column after another
+	Data []complex128
+}
+
+// isSizeValid checks to make sure a matrix's dimensions and data length match.
+func (m *ComplexMatrix) isSizeValid() bool {
+	return m.Nrow*m.Ncol == len(m.Data)
+}
+
+// Array is a representation of an R array with any number of dimensions, like a time x latitude x longitude grid. Like
+// a Matrix, its data are stored in a single slice in R's column-major order, so that the first index changes the
+// fastest. For example, the element at index [i, j, k] of an array with dimensions [I, J, K] is at position
+// i + I*j + I*J*k of the data slice.
+//
+// An Array can also have names along each dimension, which mirror the dimnames of an array in R. If it has them, there
+// is one element for each dimension, which is either nil (no names) or has one name for each index of the dimension.
+type Array struct {
+	// The size of each dimension
+	Dim []int
+
+	// The data in an array is represented as a single slice of data
+	Data []float64
+
+	// Optional names along each dimension
+	Dimnames [][]string
+}
+
+// isSizeValid checks to make sure an array's dimensions and data length match.
+func (a *Array) isSizeValid() bool {
+	size := 1
+	for _, d := range a.Dim {
+		if d < 0 {
+			return false
+		}
+		size *= d
+	}
+	return size == len(a.Data)
+}
+
+// areNamesValid checks to make sure an array's names, if it has them, match its dimensions.
+func (a *Array) areNamesValid() bool {
+	if a.Dimnames == nil {
+		return true
+	}
+	if len(a.Dimnames) != len(a.Dim) {
+		return false
+	}
+	for k, names := range a.Dimnames {
+		if names != nil && len(names) != a.Dim[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// TypedMatrix is the same as a Matrix, except that its data can be doubles, integers, or logicals. It mirrors the
+// fact that a matrix in R can have any of those types, and its data are organized the same way as a Matrix, so that
+// column indices are together.
+//
+// Because a TypedMatrix can hold integers and logicals, which can't be NaN, it also has an NA slice which marks the
+// elements that are NA in R. A nil NA slice means that no elements are NA. Elements which are NA are always the zero
+// value in the data slice.
+type TypedMatrix[t RMatrixData] struct {
+	// The Matrix header - two integers which specify its dimension
+	Nrow, Ncol int
+
+	// The data in a matrix is represented as a single slice of data
+	Data []t
+
+	// Optional mask of which elements are NA
+	NA []bool
+
+	// Optional names for each row and column
+	RowNames, ColNames []string
+}
+
+// isSizeValid checks to make sure a matrix's dimensions and data length match.
+func (m *TypedMatrix[t]) isSizeValid() bool {
+	return m.Nrow*m.Ncol == len(m.Data)
+}
@@ -0,0 +1,118 @@
+package rgo
+
+/*
+#define USE_RINTERNALS
+#include <Rinternals.h>
+// REAL, INTEGER, LOGICAL, COMPLEX, and RAW are macros, which cgo can't call directly
+static double *realPointer(SEXP s) {
+	return REAL(s);
+}
+static int *integerPointer(SEXP s) {
+	return INTEGER(s);
+}
+static int *logicalPointer(SEXP s) {
+	return LOGICAL(s);
+}
+static Rcomplex *complexPointer(SEXP s) {
+	return COMPLEX(s);
+}
+static Rbyte *rawPointer(SEXP s) {
+	return RAW(s);
+}
+*/
+import "C"
+import "unsafe"
+
+// RealView returns a slice of float64s which points directly at the data of the input RSEXP, without copying it. This
+// is much faster than AsNumeric for large vectors, but it comes with strings attached:
+//
+// The view is only valid for as long as R keeps the underlying SEXP alive. For arguments passed to a Go function with
+// .Call, that means until the Go function returns. The view must not be kept after that, such as in a global variable
+// or a goroutine that outlives the call, because R's garbage collector is free to reuse the memory.
+//
+// Writing to the view modifies the R object in place. R assumes its objects can be shared between variables, so
+// modifying an argument will also modify every other R variable that points to the same data. It is only safe to write
+// to views of vectors that were created in Go, like those from NumericToRSEXP.
+//
+// If the data in the RSEXP is not a double vector, the TypeMismatch error is returned. Callers that need a slice they
+// own should use AsReal instead.
+func RealView(r RSEXP) ([]float64, error) {
+	if TYPEOF(r) != REALSXP {
+		return nil, TypeMismatch
+	}
+	return realView(r), nil
+}
+
+// IntegerView is the same as RealView, except that it returns a view of an integer vector as a slice of int32s. NA
+// values are not treated specially, so they show up in the view as the smallest 32 bit integer. If the data in the
+// RSEXP is not an integer vector, the TypeMismatch error is returned. Callers that need a slice they own should use
+// AsInteger instead.
+func IntegerView(r RSEXP) ([]int32, error) {
+	if TYPEOF(r) != INTSXP {
+		return nil, TypeMismatch
+	}
+	return integerView(r), nil
+}
+
+// RawView is the same as RealView, except that it returns a view of a raw vector as a byte slice. If the data in the
+// RSEXP is not a raw vector, the TypeMismatch error is returned. Callers that need a slice they own should use AsRaw
+// instead.
+func RawView(r RSEXP) ([]byte, error) {
+	if TYPEOF(r) != RAWSXP {
+		return nil, TypeMismatch
+	}
+	return rawView(r), nil
+}
+
+// AsReal extracts the data from a double vector as a slice of float64s. Unlike AsNumeric, the data is copied in a
+// single bulk copy rather than element by element. The resulting slice is a new copy that can be modified
+// independently. If the data in the RSEXP is not a double vector, the TypeMismatch error is returned.
+func AsReal(r RSEXP) ([]float64, error) {
+	view, err := RealView(r)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]float64, len(view))
+	copy(out, view)
+	return out, nil
+}
+
+// AsInteger extracts the data from an integer vector as a slice of int32s. Like AsReal, the data is copied in a single
+// bulk copy, and the resulting slice is a new copy that can be modified independently. NA values are not treated
+// specially. If the data in the RSEXP is not an integer vector, the TypeMismatch error is returned.
+func AsInteger(r RSEXP) ([]int32, error) {
+	view, err := IntegerView(r)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]int32, len(view))
+	copy(out, view)
+	return out, nil
+}
+
+// realView creates the view of a REALSXP without checking its type. A C double is always a float64 on the platforms
+// that R supports, so the memory can be reinterpreted directly.
+func realView(r RSEXP) []float64 {
+	return unsafe.Slice((*float64)(unsafe.Pointer(C.realPointer(r))), LENGTH(r))
+}
+
+// integerView creates the view of an INTSXP without checking its type. R integers are always 32 bits.
+func integerView(r RSEXP) []int32 {
+	return unsafe.Slice((*int32)(unsafe.Pointer(C.integerPointer(r))), LENGTH(r))
+}
+
+// logicalView creates the view of an LGLSXP without checking its type. R stores logicals as 32 bit integers.
+func logicalView(r RSEXP) []int32 {
+	return unsafe.Slice((*int32)(unsafe.Pointer(C.logicalPointer(r))), LENGTH(r))
+}
+
+// complexView creates the view of a CPLXSXP without checking its type. An Rcomplex is a pair of doubles, the real part
+// followed by the imaginary part, which is the same layout as a complex128.
+func complexView(r RSEXP) []complex128 {
+	return unsafe.Slice((*complex128)(unsafe.Pointer(C.complexPointer(r))), LENGTH(r))
+}
+
+// rawView creates the view of a RAWSXP without checking its type. An Rbyte is an unsigned char, which is a byte.
+func rawView(r RSEXP) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(C.rawPointer(r))), LENGTH(r))
+}